@@ -0,0 +1,296 @@
+package elastic
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/cbrand/go-filterparams"
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+// OperatorFunc converts one parameter into a leaf query like
+// {"term": {"field": "value"}}.
+type OperatorFunc func(field string, value interface{}) (map[string]interface{}, error)
+
+// UnsupportedFilterError is returned if a parameter uses a filter
+// which has no registered operator.
+type UnsupportedFilterError struct {
+	Filter string
+}
+
+// Error returns the formatted error message.
+func (u *UnsupportedFilterError) Error() string {
+	return fmt.Sprintf("The filter \"%s\" can not be converted to a search query", u.Filter)
+}
+
+// Query is the translated result of a QueryData. It can be encoded with
+// encoding/json as the body of a search request.
+type Query struct {
+	// Query is the query clause. It matches all documents if no filter
+	// has been given.
+	Query map[string]interface{} `json:"query"`
+	// Sort lists the sort clauses.
+	Sort []map[string]interface{} `json:"sort,omitempty"`
+}
+
+// Translator converts parsed query data into the query DSL of
+// Elasticsearch and OpenSearch.
+type Translator struct {
+	operators map[string]OperatorFunc
+}
+
+// RegisterOperator registers the operator for the filter with the given
+// identification. Already registered operators are replaced.
+func (t *Translator) RegisterOperator(filterName string, operator OperatorFunc) *Translator {
+	t.operators[filterName] = operator
+	return t
+}
+
+// Translate converts the filter and the orders of the query data.
+func (t *Translator) Translate(data *filterparams.QueryData) (*Query, error) {
+	query, err := t.Filter(data.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+	return &Query{Query: query, Sort: t.Sort(data.GetOrders())}, nil
+}
+
+// Filter converts the filter tree into a bool query. A nil filter results
+// in a match_all query.
+func (t *Translator) Filter(filter interface{}) (map[string]interface{}, error) {
+	if filter == nil {
+		return map[string]interface{}{"match_all": map[string]interface{}{}}, nil
+	}
+	return t.translateNode(filter)
+}
+
+// Sort converts the orders into sort clauses. Orders which place null
+// values first or last set the missing option. Orders by fields of
+// relationships use the dotted path, as orders only allow to-one
+// relationships which are mapped as plain objects.
+func (t *Translator) Sort(orders []*definition.Order) []map[string]interface{} {
+	clauses := make([]map[string]interface{}, len(orders))
+	for index, order := range orders {
+		options := map[string]interface{}{"order": "asc"}
+		if order.OrderDesc() {
+			options["order"] = "desc"
+		}
+		switch order.GetNulls() {
+		case definition.NullsFirst:
+			options["missing"] = "_first"
+		case definition.NullsLast:
+			options["missing"] = "_last"
+		}
+		field := order.GetBackendName()
+		if relationships := order.GetRelationships(); len(relationships) > 0 {
+			field = relationshipPath(relationships) + definition.PathSeparator + field
+		}
+		clauses[index] = map[string]interface{}{field: options}
+	}
+	return clauses
+}
+
+// relationshipPath returns the dotted path of the relationship names.
+func relationshipPath(relationships []*definition.Relationship) string {
+	names := make([]string, len(relationships))
+	for index, relationship := range relationships {
+		names[index] = relationship.Name
+	}
+	return strings.Join(names, definition.PathSeparator)
+}
+
+// translateNode converts one node of the filter tree.
+func (t *Translator) translateNode(node interface{}) (map[string]interface{}, error) {
+	switch item := node.(type) {
+	case *definition.And:
+		return t.translateOperands("must", item)
+	case *definition.Or:
+		return t.translateOperands("should", item)
+	case *definition.Negate:
+		negated, err := t.translateNode(item.Negated)
+		if err != nil {
+			return nil, err
+		}
+		return boolQuery("must_not", negated), nil
+	case *definition.Parameter:
+		return t.translateParameter(item)
+	}
+	return nil, fmt.Errorf("Unexpected node %T in filter", node)
+}
+
+// translateOperands converts the operands of nested statements of the
+// same type into one bool query with the given occurrence type.
+func (t *Translator) translateOperands(occurrence string, node definition.Node) (map[string]interface{}, error) {
+	operands := []interface{}{}
+	for _, child := range definition.Flatten(node) {
+		operand, err := t.translateNode(child)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	return boolQuery(occurrence, operands...), nil
+}
+
+// translateParameter applies the operator of the parameter's filter.
+// Parameters of to-many relationships are wrapped in nested queries, to-one
+// relationships are expected to be mapped as plain objects.
+func (t *Translator) translateParameter(parameter *definition.Parameter) (map[string]interface{}, error) {
+	if parameter.Filter == nil {
+		return nil, &UnsupportedFilterError{}
+	}
+	operator, ok := t.operators[parameter.Filter.Identification]
+	if !ok {
+		return nil, &UnsupportedFilterError{Filter: parameter.Filter.Identification}
+	}
+	paths := make([]string, len(parameter.Relationships))
+	for index, relationship := range parameter.Relationships {
+		paths[index] = relationship.Name
+		if index > 0 {
+			paths[index] = paths[index-1] + definition.PathSeparator + relationship.Name
+		}
+	}
+	field := parameter.GetBackendName()
+	if len(paths) > 0 {
+		field = paths[len(paths)-1] + definition.PathSeparator + field
+	}
+	query, err := operator(field, parameter.Value)
+	if err != nil {
+		return nil, err
+	}
+	for index := len(paths) - 1; index >= 0; index-- {
+		if parameter.Relationships[index].ToOne {
+			continue
+		}
+		if parameter.Relationships[index].Quantifier == definition.QuantifierAll {
+			query = boolQuery("must_not", nested(paths[index], boolQuery("must_not", query)))
+		} else {
+			query = nested(paths[index], query)
+		}
+	}
+	return query, nil
+}
+
+// boolQuery returns a bool query with the operands as the given
+// occurrence type. At least one operand has to match should clauses.
+func boolQuery(occurrence string, operands ...interface{}) map[string]interface{} {
+	clauses := map[string]interface{}{occurrence: operands}
+	if occurrence == "should" {
+		clauses["minimum_should_match"] = 1
+	}
+	return map[string]interface{}{"bool": clauses}
+}
+
+// nested returns a nested query for the given path.
+func nested(path string, query map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"nested": map[string]interface{}{"path": path, "query": query}}
+}
+
+// term matches the exact value.
+func term(field string, value interface{}) (map[string]interface{}, error) {
+	return map[string]interface{}{"term": map[string]interface{}{field: value}}, nil
+}
+
+// rangeQuery returns an operator which compares the field with the given
+// range option.
+func rangeQuery(option string) OperatorFunc {
+	return func(field string, value interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"range": map[string]interface{}{field: map[string]interface{}{option: value}}}, nil
+	}
+}
+
+// terms matches the entries of a slice value. Non slice values are
+// handled as a list with one entry.
+func terms(field string, value interface{}) (map[string]interface{}, error) {
+	values := []interface{}{value}
+	reflected := reflect.ValueOf(value)
+	if reflected.Kind() == reflect.Slice || reflected.Kind() == reflect.Array {
+		values = make([]interface{}, reflected.Len())
+		for index := range values {
+			values[index] = reflected.Index(index).Interface()
+		}
+	}
+	return map[string]interface{}{"terms": map[string]interface{}{field: values}}, nil
+}
+
+// exists matches documents with a value for the field. The value is
+// ignored.
+func exists(field string, value interface{}) (map[string]interface{}, error) {
+	return map[string]interface{}{"exists": map[string]interface{}{"field": field}}, nil
+}
+
+// isNull matches documents without a value for the field. The value is
+// ignored.
+func isNull(field string, value interface{}) (map[string]interface{}, error) {
+	query, _ := exists(field, value)
+	return boolQuery("must_not", query), nil
+}
+
+// wildcard returns an operator which matches the field with a wildcard
+// query following the SQL LIKE semantics.
+func wildcard(caseInsensitive bool) OperatorFunc {
+	return func(field string, value interface{}) (map[string]interface{}, error) {
+		pattern, ok := value.(string)
+		if !ok {
+			pattern = fmt.Sprint(value)
+		}
+		options := map[string]interface{}{"value": wildcardPattern(pattern)}
+		if caseInsensitive {
+			options["case_insensitive"] = true
+		}
+		return map[string]interface{}{"wildcard": map[string]interface{}{field: options}}, nil
+	}
+}
+
+// wildcardPattern converts the SQL LIKE pattern into a wildcard pattern.
+// "%" becomes "*" and "_" becomes "?". A backslash escapes the following
+// character of the LIKE pattern.
+func wildcardPattern(pattern string) string {
+	result := ""
+	escaped := false
+	for _, char := range pattern {
+		switch {
+		case escaped:
+			result += escapeWildcard(char)
+			escaped = false
+		case char == '\\':
+			escaped = true
+		case char == '%':
+			result += "*"
+		case char == '_':
+			result += "?"
+		default:
+			result += escapeWildcard(char)
+		}
+	}
+	return result
+}
+
+// escapeWildcard escapes the characters with a special meaning in
+// wildcard patterns.
+func escapeWildcard(char rune) string {
+	if strings.ContainsRune("*?\\", char) {
+		return "\\" + string(char)
+	}
+	return string(char)
+}
+
+// NewTranslator returns a translator with operators for all filters
+// defined in the definition package.
+func NewTranslator() *Translator {
+	translator := &Translator{
+		operators: map[string]OperatorFunc{},
+	}
+	translator.RegisterOperator(definition.FilterEq.Identification, term)
+	translator.RegisterOperator(definition.FilterLt.Identification, rangeQuery("lt"))
+	translator.RegisterOperator(definition.FilterLte.Identification, rangeQuery("lte"))
+	translator.RegisterOperator(definition.FilterGt.Identification, rangeQuery("gt"))
+	translator.RegisterOperator(definition.FilterGte.Identification, rangeQuery("gte"))
+	translator.RegisterOperator(definition.FilterIn.Identification, terms)
+	translator.RegisterOperator(definition.FilterLike.Identification, wildcard(false))
+	translator.RegisterOperator(definition.FilterILike.Identification, wildcard(true))
+	translator.RegisterOperator(definition.FilterIsNull.Identification, isNull)
+	translator.RegisterOperator(definition.FilterNotNull.Identification, exists)
+	return translator
+}
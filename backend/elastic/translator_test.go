@@ -0,0 +1,101 @@
+package elastic
+
+import (
+	"net/url"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/cbrand/go-filterparams"
+	"github.com/cbrand/go-filterparams/backend/internal/golden"
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+var _ = Suite(&TranslatorTest{})
+
+type TranslatorTest struct {
+	builder *filterparams.QueryBuilder
+}
+
+func (t *TranslatorTest) SetUpTest(c *C) {
+	t.builder = golden.NewBuilder()
+}
+
+func (t *TranslatorTest) translate(c *C, query string) *Query {
+	values, err := url.ParseQuery(query)
+	c.Assert(err, IsNil)
+	queryData, err := t.builder.CreateQuery().Parse(&values)
+	c.Assert(err, IsNil)
+	translated, err := NewTranslator().Translate(queryData)
+	c.Assert(err, IsNil)
+	return translated
+}
+
+func (t *TranslatorTest) TestGolden(c *C) {
+	golden.AddFields(t.builder)
+	for name, query := range map[string]string{
+		"empty":        "",
+		"comparisons":  "filter[param][age][gte]=18&filter[param][age][lt][max]=65&filter[binding]=age%26max",
+		"or":           "filter[param][name][like]=Jo%25&filter[param][name][eq][doe]=Doe&filter[binding]=name|doe",
+		"and_flatten":  "filter[param][name]=a&filter[param][email]=b&filter[param][status]=c&filter[binding]=(name%26email)%26status",
+		"negate":       "filter[param][status][in]=deleted,banned&filter[binding]=!status",
+		"like":         "filter[param][name][like]=J_hn*%25&filter[param][email][ilike]=%25@EXAMPLE.com&filter[binding]=name%26email",
+		"like_escape":  "filter[param][name][like]=100\\%25?",
+		"null_checks":  "filter[param][email][isnull]=&filter[param][status][notnull]=&filter[binding]=email|status",
+		"relationship": "filter[param][tags.label][in]=go,search",
+		"nested_all":   "filter[param][comments.author.name]=doe",
+		"to_one":       "filter[param][company.country.code]=de",
+		"sort":         "filter[order]=desc(name)&filter[order]=age&filter[order]=asc(email,nullsfirst)",
+		"sort_nested":  "filter[order]=desc(company.name)&filter[order]=company.country.code",
+	} {
+		golden.Assert(c, name, t.translate(c, query))
+	}
+}
+
+func (t *TranslatorTest) TestUnsupportedFilter(c *C) {
+	t.builder.EnableFilter(&definition.Filter{Identification: "near"})
+	values := url.Values{"filter[param][location][near]": {"x"}}
+	queryData, err := t.builder.CreateQuery().Parse(&values)
+	c.Assert(err, IsNil)
+	_, err = NewTranslator().Translate(queryData)
+	c.Assert(err, FitsTypeOf, &UnsupportedFilterError{})
+
+	translator := NewTranslator().RegisterOperator("near", func(field string, value interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"geo_distance": map[string]interface{}{"distance": "1km", field: value}}, nil
+	})
+	translated, err := translator.Translate(queryData)
+	c.Assert(err, IsNil)
+	c.Assert(translated.Query, DeepEquals, map[string]interface{}{
+		"geo_distance": map[string]interface{}{"distance": "1km", "location": "x"},
+	})
+}
+
+func (t *TranslatorTest) TestCursor(c *C) {
+	t.builder.EnableCursorPagination("id", []byte("0123456789abcdef0123456789abcdef"))
+	c.Assert(t.translate(c, "filter[order]=name").Sort, DeepEquals, []map[string]interface{}{
+		{"name": map[string]interface{}{"order": "asc"}},
+		{"id": map[string]interface{}{"order": "asc"}},
+	})
+
+	values := url.Values{"filter[order]": {"name"}}
+	query := t.builder.CreateQuery()
+	queryData, err := query.Parse(&values)
+	c.Assert(err, IsNil)
+	cursor, err := query.EncodeCursor(queryData, map[string]interface{}{"name": "doe", "id": 7})
+	c.Assert(err, IsNil)
+	translated := t.translate(c, "filter[order]=name&page[after]="+url.QueryEscape(cursor))
+	c.Assert(translated.Query, DeepEquals, boolQuery("should",
+		map[string]interface{}{"range": map[string]interface{}{"name": map[string]interface{}{"gt": "doe"}}},
+		boolQuery("must",
+			map[string]interface{}{"term": map[string]interface{}{"name": "doe"}},
+			map[string]interface{}{"range": map[string]interface{}{"id": map[string]interface{}{"gt": "7"}}},
+		),
+	))
+}
+
+func (t *TranslatorTest) TestWildcardPattern(c *C) {
+	c.Assert(wildcardPattern("Jo%"), Equals, "Jo*")
+	c.Assert(wildcardPattern("a_c"), Equals, "a?c")
+	c.Assert(wildcardPattern("what?*"), Equals, "what\\?\\*")
+	c.Assert(wildcardPattern("100\\%\\_"), Equals, "100%_")
+	c.Assert(wildcardPattern("a\\\\b"), Equals, "a\\\\b")
+}
@@ -0,0 +1,68 @@
+package golden
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/cbrand/go-filterparams"
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// NewBuilder returns a builder with the filters the translators of the
+// document stores support.
+func NewBuilder() *filterparams.QueryBuilder {
+	builder := filterparams.NewBuilder()
+	for _, filter := range []*definition.Filter{
+		definition.FilterEq,
+		definition.FilterLt,
+		definition.FilterLte,
+		definition.FilterGt,
+		definition.FilterGte,
+		definition.FilterIn,
+		definition.FilterLike,
+		definition.FilterILike,
+		definition.FilterIsNull,
+		definition.FilterNotNull,
+	} {
+		builder.EnableFilter(filter)
+	}
+	return builder
+}
+
+// AddFields registers the fields and relationships which are used by the
+// queries of the golden files.
+func AddFields(builder *filterparams.QueryBuilder) {
+	builder.EnableField("age", "").SetFieldType("age", definition.TypeInt)
+	builder.EnableField("name", "full_name")
+	builder.EnableField("email", "")
+	builder.EnableField("status", "")
+	comments := definition.NewRelationship("comments", "comments", "id", "post_id").SetQuantifier(definition.QuantifierAll)
+	comments.AddRelationship(definition.NewRelationship("author", "users", "author_id", "id").EnableField("name", ""))
+	builder.AddRelationship(comments)
+	builder.AddRelationship(definition.NewRelationship("tags", "tags", "id", "user_id").EnableField("label", ""))
+	company := definition.NewRelationship("company", "companies", "company_id", "id").SetToOne(true).EnableField("name", "legal_name")
+	company.AddRelationship(definition.NewRelationship("country", "countries", "country_id", "id").SetToOne(true).EnableField("code", ""))
+	builder.AddRelationship(company)
+}
+
+// Assert compares the JSON representation of the value with the golden
+// file of the name in the testdata directory. The file is written if the
+// -update flag is set.
+func Assert(c *C, name string, value interface{}) {
+	actual, err := json.MarshalIndent(value, "", "  ")
+	c.Assert(err, IsNil)
+	actual = append(actual, '\n')
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		c.Assert(ioutil.WriteFile(path, actual, 0644), IsNil)
+	}
+	expected, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	c.Assert(string(actual), Equals, string(expected))
+}
@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+var decimalType = reflect.TypeOf(definition.Decimal(""))
+
+// compare compares the field value with the filter value. The filter value
+// is converted to the type of the field first.
+func compare(field reflect.Value, value interface{}) (int, error) {
+	switch {
+	case field.Type() == timeType:
+		other, err := toTime(value)
+		if err != nil {
+			return 0, err
+		}
+		fieldTime := field.Interface().(time.Time)
+		if fieldTime.Before(other) {
+			return -1, nil
+		} else if fieldTime.After(other) {
+			return 1, nil
+		}
+		return 0, nil
+	case field.Type() == decimalType || isNumeric(field.Kind()):
+		fieldNumber, err := toRat(field.Interface())
+		if err != nil {
+			return 0, err
+		}
+		other, err := toRat(value)
+		if err != nil {
+			return 0, err
+		}
+		return fieldNumber.Cmp(other), nil
+	case field.Kind() == reflect.String:
+		return strings.Compare(field.String(), toString(value)), nil
+	case field.Kind() == reflect.Bool:
+		other, err := toBool(value)
+		if err != nil {
+			return 0, err
+		}
+		return compareBool(field.Bool(), other), nil
+	}
+	return 0, fmt.Errorf("Values of type %s can not be compared", field.Type())
+}
+
+// isNumeric returns if the kind is an integer or float.
+func isNumeric(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// toRat converts numbers and their string representations to an exact
+// rational number.
+func toRat(value interface{}) (*big.Rat, error) {
+	reflected := indirect(reflect.ValueOf(value))
+	if !reflected.IsValid() {
+		return nil, fmt.Errorf("Can not compare nil with a number")
+	}
+	switch reflected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(reflected.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(reflected.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		if rat := new(big.Rat).SetFloat64(reflected.Float()); rat != nil {
+			return rat, nil
+		}
+	case reflect.String:
+		if rat, ok := new(big.Rat).SetString(reflected.String()); ok {
+			return rat, nil
+		}
+	}
+	return nil, fmt.Errorf("\"%v\" is not a number", value)
+}
+
+// toTime converts times and RFC 3339 strings to a time.
+func toTime(value interface{}) (time.Time, error) {
+	switch converted := value.(type) {
+	case time.Time:
+		return converted, nil
+	case *time.Time:
+		if converted != nil {
+			return *converted, nil
+		}
+	case string:
+		return time.Parse(time.RFC3339Nano, converted)
+	}
+	return time.Time{}, fmt.Errorf("\"%v\" is not a time", value)
+}
+
+// toBool converts booleans and their string representation to a bool.
+func toBool(value interface{}) (bool, error) {
+	switch converted := value.(type) {
+	case bool:
+		return converted, nil
+	case string:
+		return strconv.ParseBool(converted)
+	}
+	return false, fmt.Errorf("\"%v\" is not a boolean", value)
+}
+
+// toString returns the string representation of the value.
+func toString(value interface{}) string {
+	reflected := indirect(reflect.ValueOf(value))
+	if reflected.IsValid() && reflected.Kind() == reflect.String {
+		return reflected.String()
+	}
+	return fmt.Sprint(value)
+}
+
+// compareBool orders false before true.
+func compareBool(left, right bool) int {
+	switch {
+	case left == right:
+		return 0
+	case right:
+		return -1
+	}
+	return 1
+}
+
+// compareFields compares two field values for sorting. Nil values are
+// sorted after all other values.
+func compareFields(left, right reflect.Value) (int, error) {
+	left, right = indirect(left), indirect(right)
+	switch {
+	case !left.IsValid() && !right.IsValid():
+		return 0, nil
+	case !left.IsValid():
+		return 1, nil
+	case !right.IsValid():
+		return -1, nil
+	}
+	return compare(left, right.Interface())
+}
+
+// likePattern converts the SQL LIKE pattern into a regular expression. A
+// backslash escapes the following character.
+func likePattern(pattern string, caseInsensitive bool) (*regexp.Regexp, error) {
+	expression := "(?s)^"
+	if caseInsensitive {
+		expression = "(?i)" + expression
+	}
+	escaped := false
+	for _, char := range pattern {
+		switch {
+		case escaped:
+			expression += regexp.QuoteMeta(string(char))
+			escaped = false
+		case char == '\\':
+			escaped = true
+		case char == '%':
+			expression += ".*"
+		case char == '_':
+			expression += "."
+		default:
+			expression += regexp.QuoteMeta(string(char))
+		}
+	}
+	return regexp.Compile(expression + "$")
+}
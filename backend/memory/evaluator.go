@@ -0,0 +1,278 @@
+package memory
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/cbrand/go-filterparams"
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+const defaultTagName = "filter"
+
+// OperatorFunc returns if the field matches the value of a filter. The
+// field is invalid if it is nil.
+type OperatorFunc func(field reflect.Value, value interface{}) (bool, error)
+
+// UnsupportedFilterError is returned if a parameter uses a filter
+// which has no registered operator.
+type UnsupportedFilterError struct {
+	Filter string
+}
+
+// Error returns the formatted error message.
+func (u *UnsupportedFilterError) Error() string {
+	return fmt.Sprintf("The filter \"%s\" can not be evaluated", u.Filter)
+}
+
+// Evaluator applies parsed query data to slices of structs.
+type Evaluator struct {
+	tagName   string
+	operators map[string]OperatorFunc
+}
+
+// SetTagName sets the struct tag which is used to resolve the parameter
+// names. Defaults to "filter". The json tag is used as a fallback.
+func (e *Evaluator) SetTagName(tagName string) *Evaluator {
+	e.tagName = tagName
+	return e
+}
+
+// RegisterOperator registers the operator for the filter with the given
+// identification. Already registered operators are replaced.
+func (e *Evaluator) RegisterOperator(filterName string, operator OperatorFunc) *Evaluator {
+	e.operators[filterName] = operator
+	return e
+}
+
+// Apply filters the items by the filter of the query data and sorts them
+// by its orders. Items must be a slice of structs or pointers to structs.
+// A new slice of the same type is returned.
+func (e *Evaluator) Apply(data *filterparams.QueryData, items interface{}) (interface{}, error) {
+	slice := reflect.ValueOf(items)
+	if slice.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("Expected a slice, got %T", items)
+	}
+	result := reflect.MakeSlice(slice.Type(), 0, slice.Len())
+	for index := 0; index < slice.Len(); index++ {
+		item := slice.Index(index)
+		matches, err := e.match(data.GetFilter(), item)
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			result = reflect.Append(result, item)
+		}
+	}
+	if err := e.Sort(data.GetOrders(), result.Interface()); err != nil {
+		return nil, err
+	}
+	return result.Interface(), nil
+}
+
+// Match returns if the item matches the filter. A nil filter matches
+// every item.
+func (e *Evaluator) Match(filter interface{}, item interface{}) (bool, error) {
+	return e.match(filter, reflect.ValueOf(item))
+}
+
+// match evaluates the filter node against the item.
+func (e *Evaluator) match(node interface{}, item reflect.Value) (bool, error) {
+	switch statement := node.(type) {
+	case nil:
+		return true, nil
+	case *definition.And:
+		left, err := e.match(statement.Left, item)
+		if err != nil || !left {
+			return false, err
+		}
+		return e.match(statement.Right, item)
+	case *definition.Or:
+		left, err := e.match(statement.Left, item)
+		if err != nil || left {
+			return left, err
+		}
+		return e.match(statement.Right, item)
+	case *definition.Negate:
+		negated, err := e.match(statement.Negated, item)
+		return !negated, err
+	case *definition.Parameter:
+		return e.matchParameter(statement, item)
+	}
+	return false, fmt.Errorf("Unexpected node %T in filter", node)
+}
+
+// matchParameter applies the operator of the parameter's filter.
+func (e *Evaluator) matchParameter(parameter *definition.Parameter, item reflect.Value) (bool, error) {
+	if parameter.Filter == nil {
+		return false, &UnsupportedFilterError{}
+	}
+	operator, ok := e.operators[parameter.Filter.Identification]
+	if !ok {
+		return false, &UnsupportedFilterError{Filter: parameter.Filter.Identification}
+	}
+	return e.matchPath(parameter, operator, item, 0)
+}
+
+// matchPath resolves the segment of the parameter's path with the given
+// index and applies the operator to the last one. Nil relationships never
+// match. Slices match if any element matches unless the relationship of
+// the segment uses QuantifierAll.
+func (e *Evaluator) matchPath(parameter *definition.Parameter, operator OperatorFunc, item reflect.Value, index int) (bool, error) {
+	path := parameter.GetPath()
+	field, err := e.resolveField(item, path[index])
+	if err != nil {
+		return false, err
+	}
+	if index == len(path)-1 {
+		return operator(indirect(field), parameter.Value)
+	}
+	related := indirect(field)
+	if !related.IsValid() {
+		return false, nil
+	}
+	if related.Kind() != reflect.Slice && related.Kind() != reflect.Array {
+		return e.matchPath(parameter, operator, related, index+1)
+	}
+	all := index < len(parameter.Relationships) && parameter.Relationships[index].Quantifier == definition.QuantifierAll
+	for position := 0; position < related.Len(); position++ {
+		matches, err := e.matchPath(parameter, operator, related.Index(position), index+1)
+		if err != nil {
+			return false, err
+		}
+		if matches != all {
+			return matches, nil
+		}
+	}
+	return all, nil
+}
+
+// Sort sorts the slice in place by the given orders. Items which are
+// equal for all orders keep their relative position. Nil values are
+// larger than all other values unless the order places them first or last.
+func (e *Evaluator) Sort(orders []*definition.Order, items interface{}) error {
+	slice := reflect.ValueOf(items)
+	if slice.Kind() != reflect.Slice {
+		return fmt.Errorf("Expected a slice, got %T", items)
+	}
+	if len(orders) == 0 {
+		return nil
+	}
+	var sortErr error
+	sort.SliceStable(items, func(i, j int) bool {
+		if sortErr != nil {
+			return false
+		}
+		for _, order := range orders {
+			left, err := e.resolvePath(slice.Index(i), order.GetOrderBy())
+			if err != nil {
+				sortErr = err
+				return false
+			}
+			right, err := e.resolvePath(slice.Index(j), order.GetOrderBy())
+			if err != nil {
+				sortErr = err
+				return false
+			}
+			leftNull, rightNull := !indirect(left).IsValid(), !indirect(right).IsValid()
+			if order.GetNulls() != definition.NullsDefault && leftNull != rightNull {
+				return leftNull == (order.GetNulls() == definition.NullsFirst)
+			}
+			result, err := compareFields(left, right)
+			if err != nil {
+				sortErr = err
+				return false
+			}
+			if result != 0 {
+				return (result < 0) != order.OrderDesc()
+			}
+		}
+		return false
+	})
+	return sortErr
+}
+
+// comparison returns an operator which matches if the comparison of the
+// field with the value fulfills the check. Nil fields never match.
+func comparison(check func(result int) bool) OperatorFunc {
+	return func(field reflect.Value, value interface{}) (bool, error) {
+		if !field.IsValid() {
+			return false, nil
+		}
+		result, err := compare(field, value)
+		if err != nil {
+			return false, err
+		}
+		return check(result), nil
+	}
+}
+
+// in matches if the field equals one of the entries of a slice value. Non
+// slice values are handled as a list with one entry.
+func in(field reflect.Value, value interface{}) (bool, error) {
+	if !field.IsValid() {
+		return false, nil
+	}
+	values := []interface{}{value}
+	reflected := reflect.ValueOf(value)
+	if reflected.Kind() == reflect.Slice || reflected.Kind() == reflect.Array {
+		values = make([]interface{}, reflected.Len())
+		for index := range values {
+			values[index] = reflected.Index(index).Interface()
+		}
+	}
+	for _, entry := range values {
+		result, err := compare(field, entry)
+		if err != nil {
+			return false, err
+		}
+		if result == 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isNull returns an operator which matches nil fields if null is set and
+// all other fields otherwise. The value is ignored.
+func isNull(null bool) OperatorFunc {
+	return func(field reflect.Value, value interface{}) (bool, error) {
+		return !field.IsValid() == null, nil
+	}
+}
+
+// like returns an operator which matches the field with SQL LIKE
+// semantics.
+func like(caseInsensitive bool) OperatorFunc {
+	return func(field reflect.Value, value interface{}) (bool, error) {
+		if !field.IsValid() {
+			return false, nil
+		}
+		pattern, err := likePattern(toString(value), caseInsensitive)
+		if err != nil {
+			return false, err
+		}
+		return pattern.MatchString(toString(field.Interface())), nil
+	}
+}
+
+// NewEvaluator returns an evaluator with operators for all filters defined
+// in the definition package.
+func NewEvaluator() *Evaluator {
+	evaluator := &Evaluator{
+		tagName:   defaultTagName,
+		operators: map[string]OperatorFunc{},
+	}
+	evaluator.RegisterOperator(definition.FilterEq.Identification, comparison(func(result int) bool { return result == 0 }))
+	evaluator.RegisterOperator(definition.FilterLt.Identification, comparison(func(result int) bool { return result < 0 }))
+	evaluator.RegisterOperator(definition.FilterLte.Identification, comparison(func(result int) bool { return result <= 0 }))
+	evaluator.RegisterOperator(definition.FilterGt.Identification, comparison(func(result int) bool { return result > 0 }))
+	evaluator.RegisterOperator(definition.FilterGte.Identification, comparison(func(result int) bool { return result >= 0 }))
+	evaluator.RegisterOperator(definition.FilterIn.Identification, in)
+	evaluator.RegisterOperator(definition.FilterLike.Identification, like(false))
+	evaluator.RegisterOperator(definition.FilterILike.Identification, like(true))
+	evaluator.RegisterOperator(definition.FilterIsNull.Identification, isNull(true))
+	evaluator.RegisterOperator(definition.FilterNotNull.Identification, isNull(false))
+	return evaluator
+}
@@ -0,0 +1,280 @@
+package memory
+
+import (
+	"net/url"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/cbrand/go-filterparams"
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+var _ = Suite(&EvaluatorTest{})
+
+type user struct {
+	Name     string `filter:"name"`
+	Email    string `json:"email,omitempty"`
+	Age      int
+	Balance  *float64  `filter:"balance"`
+	Active   bool      `filter:"active"`
+	Created  time.Time `filter:"created"`
+	Password string    `filter:"-"`
+	Company  *company  `filter:"company"`
+	Roles    []role    `filter:"roles"`
+}
+
+type company struct {
+	Name string `filter:"name"`
+}
+
+type role struct {
+	Name  string `filter:"name"`
+	Admin bool   `filter:"admin"`
+}
+
+type EvaluatorTest struct {
+	builder *filterparams.QueryBuilder
+	data    *url.Values
+	users   []*user
+}
+
+func float(value float64) *float64 {
+	return &value
+}
+
+func (t *EvaluatorTest) SetUpTest(c *C) {
+	t.builder = filterparams.NewBuilder()
+	for _, filter := range []*definition.Filter{
+		definition.FilterEq,
+		definition.FilterLt,
+		definition.FilterLte,
+		definition.FilterGt,
+		definition.FilterGte,
+		definition.FilterIn,
+		definition.FilterLike,
+		definition.FilterILike,
+		definition.FilterIsNull,
+		definition.FilterNotNull,
+	} {
+		t.builder.EnableFilter(filter)
+	}
+	t.data = &url.Values{}
+	t.users = []*user{
+		{Name: "John Doe", Email: "john@example.com", Age: 30, Balance: float(10.5), Active: true, Created: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "Jane Doe", Email: "jane@example.com", Age: 25, Active: false, Created: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "Max 100%", Email: "max@example.org", Age: 30, Balance: float(-2), Active: true, Created: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+}
+
+func (t *EvaluatorTest) apply(c *C) []string {
+	queryData, err := t.builder.CreateQuery().Parse(t.data)
+	c.Assert(err, IsNil)
+	result, err := NewEvaluator().Apply(queryData, t.users)
+	c.Assert(err, IsNil)
+	names := []string{}
+	for _, item := range result.([]*user) {
+		names = append(names, item.Name)
+	}
+	return names
+}
+
+func (t *EvaluatorTest) TestEmpty(c *C) {
+	c.Assert(t.apply(c), DeepEquals, []string{"John Doe", "Jane Doe", "Max 100%"})
+}
+
+func (t *EvaluatorTest) TestComparisons(c *C) {
+	for _, testCase := range []struct {
+		key      string
+		value    string
+		expected []string
+	}{
+		{"filter[param][age][eq]", "30", []string{"John Doe", "Max 100%"}},
+		{"filter[param][age][lt]", "30", []string{"Jane Doe"}},
+		{"filter[param][age][lte]", "30", []string{"John Doe", "Jane Doe", "Max 100%"}},
+		{"filter[param][age][gt]", "30", []string{}},
+		{"filter[param][age][gte]", "30", []string{"John Doe", "Max 100%"}},
+		{"filter[param][balance][gt]", "0", []string{"John Doe"}},
+		{"filter[param][active][eq]", "true", []string{"John Doe", "Max 100%"}},
+		{"filter[param][created][gte]", "2016-01-01T00:00:00Z", []string{"Jane Doe", "Max 100%"}},
+	} {
+		t.data = &url.Values{}
+		t.data.Set(testCase.key, testCase.value)
+		c.Assert(t.apply(c), DeepEquals, testCase.expected, Commentf(testCase.key))
+	}
+}
+
+func (t *EvaluatorTest) TestTypedValues(c *C) {
+	t.builder.SetFieldType("balance", definition.TypeDecimal)
+	t.data.Set("filter[param][balance][lt]", "10.50")
+	c.Assert(t.apply(c), DeepEquals, []string{"Max 100%"})
+}
+
+func (t *EvaluatorTest) TestIn(c *C) {
+	t.builder.SetFieldType("age", definition.TypeInt)
+	t.data.Set("filter[param][age][in]", "25,40")
+	c.Assert(t.apply(c), DeepEquals, []string{"Jane Doe"})
+}
+
+func (t *EvaluatorTest) TestLike(c *C) {
+	t.data.Set("filter[param][name][like]", "J%Doe")
+	c.Assert(t.apply(c), DeepEquals, []string{"John Doe", "Jane Doe"})
+
+	t.data = &url.Values{}
+	t.data.Set("filter[param][name][like]", "J_ne%")
+	c.Assert(t.apply(c), DeepEquals, []string{"Jane Doe"})
+
+	t.data = &url.Values{}
+	t.data.Set("filter[param][name][like]", "%100\\%")
+	c.Assert(t.apply(c), DeepEquals, []string{"Max 100%"})
+
+	t.data = &url.Values{}
+	t.data.Set("filter[param][name][like]", "john%")
+	c.Assert(t.apply(c), DeepEquals, []string{})
+}
+
+func (t *EvaluatorTest) TestILike(c *C) {
+	t.data.Set("filter[param][email][ilike]", "%EXAMPLE.COM")
+	c.Assert(t.apply(c), DeepEquals, []string{"John Doe", "Jane Doe"})
+}
+
+func (t *EvaluatorTest) TestNullChecks(c *C) {
+	t.data.Set("filter[param][balance][isnull]", "")
+	c.Assert(t.apply(c), DeepEquals, []string{"Jane Doe"})
+	t.data.Del("filter[param][balance][isnull]")
+	t.data.Set("filter[param][balance][notnull]", "")
+	c.Assert(t.apply(c), DeepEquals, []string{"John Doe", "Max 100%"})
+}
+
+func (t *EvaluatorTest) TestBinding(c *C) {
+	t.data.Set("filter[param][name][like][doe]", "%Doe")
+	t.data.Set("filter[param][age][eq][young]", "25")
+	t.data.Set("filter[param][balance][lt][negative]", "0")
+	t.data.Set("filter[binding]", "(doe&!young)|negative")
+	c.Assert(t.apply(c), DeepEquals, []string{"John Doe", "Max 100%"})
+}
+
+func (t *EvaluatorTest) TestNilNeverMatches(c *C) {
+	t.data.Set("filter[param][balance][lt]", "100")
+	c.Assert(t.apply(c), DeepEquals, []string{"John Doe", "Max 100%"})
+}
+
+func (t *EvaluatorTest) TestSort(c *C) {
+	t.data.Add("filter[order]", "desc(age)")
+	t.data.Add("filter[order]", "name")
+	c.Assert(t.apply(c), DeepEquals, []string{"John Doe", "Max 100%", "Jane Doe"})
+}
+
+func (t *EvaluatorTest) TestSortStable(c *C) {
+	t.data.Add("filter[order]", "desc(age)")
+	c.Assert(t.apply(c), DeepEquals, []string{"John Doe", "Max 100%", "Jane Doe"})
+}
+
+func (t *EvaluatorTest) TestSortNilLast(c *C) {
+	t.data.Add("filter[order]", "balance")
+	c.Assert(t.apply(c), DeepEquals, []string{"Max 100%", "John Doe", "Jane Doe"})
+}
+
+func (t *EvaluatorTest) TestSortNulls(c *C) {
+	t.data.Add("filter[order]", "asc(balance,nullsfirst)")
+	c.Assert(t.apply(c), DeepEquals, []string{"Jane Doe", "Max 100%", "John Doe"})
+	t.data.Set("filter[order]", "desc(balance,nullslast)")
+	c.Assert(t.apply(c), DeepEquals, []string{"John Doe", "Max 100%", "Jane Doe"})
+}
+
+func (t *EvaluatorTest) TestUnknownField(c *C) {
+	t.data.Set("filter[param][password][eq]", "secret")
+	queryData, err := t.builder.CreateQuery().Parse(t.data)
+	c.Assert(err, IsNil)
+	_, err = NewEvaluator().Apply(queryData, t.users)
+	fieldErr, ok := err.(*UnknownFieldError)
+	c.Assert(ok, Equals, true)
+	c.Assert(fieldErr.Field, Equals, "password")
+}
+
+func (t *EvaluatorTest) TestMatchValues(c *C) {
+	parameter := definition.NewParameter("name")
+	parameter.Name = "name"
+	parameter.Filter = definition.FilterEq
+	parameter.Value = "Jane Doe"
+	matches, err := NewEvaluator().Match(parameter, *t.users[1])
+	c.Assert(err, IsNil)
+	c.Assert(matches, Equals, true)
+}
+
+func (t *EvaluatorTest) setUpRelationships() {
+	t.users[0].Company = &company{Name: "Acme"}
+	t.users[0].Roles = []role{{Name: "editor"}, {Name: "owner", Admin: true}}
+	t.users[1].Company = &company{Name: "Initech"}
+	t.users[1].Roles = []role{{Name: "owner", Admin: true}}
+}
+
+func (t *EvaluatorTest) TestNestedStruct(c *C) {
+	t.setUpRelationships()
+	t.data.Set("filter[param][company.name]", "Acme")
+	c.Assert(t.apply(c), DeepEquals, []string{"John Doe"})
+
+	t.data.Set("filter[binding]", "!company.name")
+	c.Assert(t.apply(c), DeepEquals, []string{"Jane Doe", "Max 100%"})
+}
+
+func (t *EvaluatorTest) TestSliceAny(c *C) {
+	t.setUpRelationships()
+	t.data.Set("filter[param][roles.name]", "editor")
+	c.Assert(t.apply(c), DeepEquals, []string{"John Doe"})
+}
+
+func (t *EvaluatorTest) TestSliceAll(c *C) {
+	t.setUpRelationships()
+	roles := definition.NewRelationship("roles", "roles", "id", "user_id").SetQuantifier(definition.QuantifierAll)
+	roles.AddField(definition.NewField("admin", "").SetType(definition.TypeBool))
+	t.builder.AddRelationship(roles)
+	t.data.Set("filter[param][roles.admin]", "true")
+	c.Assert(t.apply(c), DeepEquals, []string{"Jane Doe", "Max 100%"})
+}
+
+func (t *EvaluatorTest) TestSortNested(c *C) {
+	t.setUpRelationships()
+	t.data.Add("filter[order]", "desc(company.name)")
+	c.Assert(t.apply(c), DeepEquals, []string{"Max 100%", "Jane Doe", "John Doe"})
+}
+
+func (t *EvaluatorTest) TestCursor(c *C) {
+	t.builder.SetFieldType("age", definition.TypeInt).EnableCursorPagination("name", []byte("0123456789abcdef0123456789abcdef"))
+	t.data.Set("filter[order]", "age")
+	c.Assert(t.apply(c), DeepEquals, []string{"Jane Doe", "John Doe", "Max 100%"})
+
+	query := t.builder.CreateQuery()
+	queryData, err := query.Parse(t.data)
+	c.Assert(err, IsNil)
+	cursor, err := query.EncodeCursor(queryData, map[string]interface{}{"age": 30, "name": "John Doe"})
+	c.Assert(err, IsNil)
+	t.data.Set("page[after]", cursor)
+	c.Assert(t.apply(c), DeepEquals, []string{"Max 100%"})
+
+	t.data.Del("page[after]")
+	t.data.Set("page[before]", cursor)
+	c.Assert(t.apply(c), DeepEquals, []string{"Jane Doe"})
+}
+
+func (t *EvaluatorTest) TestCursorNulls(c *C) {
+	t.builder.EnableCursorPagination("name", []byte("0123456789abcdef0123456789abcdef"))
+	t.data.Set("filter[order]", "asc(balance,nullslast)")
+	c.Assert(t.apply(c), DeepEquals, []string{"Max 100%", "John Doe", "Jane Doe"})
+
+	query := t.builder.CreateQuery()
+	queryData, err := query.Parse(t.data)
+	c.Assert(err, IsNil)
+	cursor, err := query.EncodeCursor(queryData, map[string]interface{}{"balance": float(10.5), "name": "John Doe"})
+	c.Assert(err, IsNil)
+	t.data.Set("page[after]", cursor)
+	c.Assert(t.apply(c), DeepEquals, []string{"Jane Doe"})
+
+	cursor, err = query.EncodeCursor(queryData, map[string]interface{}{"balance": nil, "name": "Jane Doe"})
+	c.Assert(err, IsNil)
+	t.data.Set("page[after]", cursor)
+	c.Assert(t.apply(c), DeepEquals, []string{})
+	t.data.Del("page[after]")
+	t.data.Set("page[before]", cursor)
+	c.Assert(t.apply(c), DeepEquals, []string{"John Doe", "Max 100%"})
+}
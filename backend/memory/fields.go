@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+// UnknownFieldError is returned if a name can not be resolved to a field
+// of the evaluated struct.
+type UnknownFieldError struct {
+	Field string
+	Type  reflect.Type
+}
+
+// Error returns the formatted error message.
+func (u *UnknownFieldError) Error() string {
+	return fmt.Sprintf("The field \"%s\" does not exist on %s", u.Field, u.Type)
+}
+
+// indirect dereferences pointers and interfaces. The returned value is
+// invalid if a nil value has been encountered.
+func indirect(value reflect.Value) reflect.Value {
+	for value.IsValid() && (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// fieldName returns the name the struct field is referenced by. The tag
+// has precedence over the json tag.
+func fieldName(field reflect.StructField, tagName string) string {
+	for _, tag := range []string{tagName, "json"} {
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if len(name) > 0 {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// resolveField returns the value of the field with the given name. Names
+// without a tag are matched case insensitively against the Go field name.
+func (e *Evaluator) resolveField(item reflect.Value, name string) (reflect.Value, error) {
+	structValue := indirect(item)
+	if !structValue.IsValid() {
+		return reflect.Value{}, nil
+	}
+	if structValue.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("Expected a struct, got %s", structValue.Type())
+	}
+	structType := structValue.Type()
+	for index := 0; index < structType.NumField(); index++ {
+		field := structType.Field(index)
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		referenced := fieldName(field, e.tagName)
+		if referenced == name || (referenced == field.Name && strings.EqualFold(referenced, name)) {
+			return structValue.Field(index), nil
+		}
+	}
+	return reflect.Value{}, &UnknownFieldError{Field: name, Type: structType}
+}
+
+// resolvePath resolves a dotted name through nested structs. The returned
+// value is invalid if a nil struct has been encountered.
+func (e *Evaluator) resolvePath(item reflect.Value, name string) (reflect.Value, error) {
+	value := item
+	for _, segment := range definition.SplitPath(name) {
+		field, err := e.resolveField(value, segment)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value = field
+	}
+	return value, nil
+}
@@ -0,0 +1,309 @@
+package mongo
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/cbrand/go-filterparams"
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+// OperatorFunc converts the value of a parameter into an operator
+// expression like {"$gt": 5} which is applied to the field.
+type OperatorFunc func(value interface{}) (map[string]interface{}, error)
+
+// UnsupportedFilterError is returned if a parameter uses a filter
+// which has no registered operator.
+type UnsupportedFilterError struct {
+	Filter string
+}
+
+// Error returns the formatted error message.
+func (u *UnsupportedFilterError) Error() string {
+	return fmt.Sprintf("The filter \"%s\" can not be converted to a MongoDB query", u.Filter)
+}
+
+// UnsupportedQuantifierError is returned if a parameter filters a
+// relationship with a quantifier MongoDB can't express.
+type UnsupportedQuantifierError struct {
+	Relationship string
+}
+
+// Error returns the formatted error message.
+func (u *UnsupportedQuantifierError) Error() string {
+	return fmt.Sprintf("The quantifier of the relationship \"%s\" is not supported by MongoDB", u.Relationship)
+}
+
+// UnsupportedNullsError is returned if an order places null values where
+// MongoDB can't put them. MongoDB always sorts null values first in
+// ascending and last in descending order.
+type UnsupportedNullsError struct {
+	OrderBy string
+}
+
+// Error returns the formatted error message.
+func (u *UnsupportedNullsError) Error() string {
+	return fmt.Sprintf("The null placement of the order \"%s\" is not supported by MongoDB", u.OrderBy)
+}
+
+// SortEntry is one field of a sort document. The entries are returned as
+// a slice as the order of the fields is relevant. They can be converted
+// to the ordered document type of the used driver.
+type SortEntry struct {
+	Key string
+	// Value is 1 for ascending and -1 for descending order.
+	Value int
+}
+
+// Query is the translated result of a QueryData.
+type Query struct {
+	// Filter is the filter document. It is empty if no filter has been
+	// given and thus matches all documents.
+	Filter map[string]interface{}
+	// Sort lists the fields to sort by.
+	Sort []SortEntry
+}
+
+// Translator converts parsed query data into MongoDB query documents
+// which don't depend on a specific driver.
+type Translator struct {
+	operators map[string]OperatorFunc
+}
+
+// RegisterOperator registers the operator for the filter with the given
+// identification. Already registered operators are replaced.
+func (t *Translator) RegisterOperator(filterName string, operator OperatorFunc) *Translator {
+	t.operators[filterName] = operator
+	return t
+}
+
+// Translate converts the filter and the orders of the query data.
+func (t *Translator) Translate(data *filterparams.QueryData) (*Query, error) {
+	filter, err := t.Filter(data.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+	sort, err := t.Sort(data.GetOrders())
+	if err != nil {
+		return nil, err
+	}
+	return &Query{Filter: filter, Sort: sort}, nil
+}
+
+// Filter converts the filter tree into a filter document. A nil filter
+// results in an empty document.
+func (t *Translator) Filter(filter interface{}) (map[string]interface{}, error) {
+	if filter == nil {
+		return map[string]interface{}{}, nil
+	}
+	return t.translateNode(filter)
+}
+
+// Sort converts the orders into the entries of a sort document.
+func (t *Translator) Sort(orders []*definition.Order) ([]SortEntry, error) {
+	entries := make([]SortEntry, len(orders))
+	for index, order := range orders {
+		entries[index] = SortEntry{Key: orderPath(order), Value: 1}
+		if order.OrderDesc() {
+			entries[index].Value = -1
+		}
+		nulls := order.GetNulls()
+		if (nulls == definition.NullsFirst && order.OrderDesc()) || (nulls == definition.NullsLast && !order.OrderDesc()) {
+			return nil, &UnsupportedNullsError{OrderBy: order.GetOrderBy()}
+		}
+	}
+	return entries, nil
+}
+
+// translateNode converts one node of the filter tree.
+func (t *Translator) translateNode(node interface{}) (map[string]interface{}, error) {
+	switch item := node.(type) {
+	case *definition.And:
+		return t.translateOperands("$and", item)
+	case *definition.Or:
+		return t.translateOperands("$or", item)
+	case *definition.Negate:
+		return t.translateNegate(item)
+	case *definition.Parameter:
+		field, expression, err := t.translateParameter(item)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{field: expression}, nil
+	}
+	return nil, fmt.Errorf("Unexpected node %T in filter", node)
+}
+
+// translateOperands converts the operands of nested statements of the
+// same type into one list of the given logical operator.
+func (t *Translator) translateOperands(operator string, node definition.Node) (map[string]interface{}, error) {
+	operands := []interface{}{}
+	for _, child := range definition.Flatten(node) {
+		operand, err := t.translateNode(child)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	return map[string]interface{}{operator: operands}, nil
+}
+
+// translateNegate negates a parameter with $not and all other nodes with
+// $nor. A negated Or statement is converted into a $nor of its operands.
+func (t *Translator) translateNegate(negate *definition.Negate) (map[string]interface{}, error) {
+	switch negated := negate.Negated.(type) {
+	case *definition.Parameter:
+		field, expression, err := t.translateParameter(negated)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{field: map[string]interface{}{"$not": expression}}, nil
+	case *definition.Or:
+		return t.translateOperands("$nor", negated)
+	}
+	operand, err := t.translateNode(negate.Negated)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"$nor": []interface{}{operand}}, nil
+}
+
+// translateParameter returns the field and the operator expression of the
+// parameter.
+func (t *Translator) translateParameter(parameter *definition.Parameter) (string, map[string]interface{}, error) {
+	if parameter.Filter == nil {
+		return "", nil, &UnsupportedFilterError{}
+	}
+	operator, ok := t.operators[parameter.Filter.Identification]
+	if !ok {
+		return "", nil, &UnsupportedFilterError{Filter: parameter.Filter.Identification}
+	}
+	field, err := fieldPath(parameter)
+	if err != nil {
+		return "", nil, err
+	}
+	expression, err := operator(parameter.Value)
+	if err != nil {
+		return "", nil, err
+	}
+	return field, expression, nil
+}
+
+// fieldPath returns the dotted path of the field in the document. The
+// segments of relationships are kept as MongoDB matches arrays of embedded
+// documents if any of them matches.
+func fieldPath(parameter *definition.Parameter) (string, error) {
+	if len(parameter.Relationships) == 0 {
+		return parameter.GetBackendName(), nil
+	}
+	segments := []string{}
+	for _, relationship := range parameter.Relationships {
+		if relationship.Quantifier != definition.QuantifierAny {
+			return "", &UnsupportedQuantifierError{Relationship: relationship.Name}
+		}
+		segments = append(segments, relationship.Name)
+	}
+	return strings.Join(append(segments, parameter.GetBackendName()), definition.PathSeparator), nil
+}
+
+// orderPath returns the dotted path of the order's field through the
+// names of its relationships.
+func orderPath(order *definition.Order) string {
+	segments := []string{}
+	for _, relationship := range order.GetRelationships() {
+		segments = append(segments, relationship.Name)
+	}
+	return strings.Join(append(segments, order.GetBackendName()), definition.PathSeparator)
+}
+
+// comparison returns an operator which compares the field with the
+// given MongoDB operator.
+func comparison(mongoOperator string) OperatorFunc {
+	return func(value interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{mongoOperator: value}, nil
+	}
+}
+
+// nullCheck returns an operator which compares the field with null using
+// the given MongoDB operator. Missing fields are null as well. The value is
+// ignored.
+func nullCheck(mongoOperator string) OperatorFunc {
+	return func(value interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{mongoOperator: nil}, nil
+	}
+}
+
+// in matches the entries of a slice value. Non slice values are handled
+// as a list with one entry.
+func in(value interface{}) (map[string]interface{}, error) {
+	values := []interface{}{value}
+	reflected := reflect.ValueOf(value)
+	if reflected.Kind() == reflect.Slice || reflected.Kind() == reflect.Array {
+		values = make([]interface{}, reflected.Len())
+		for index := range values {
+			values[index] = reflected.Index(index).Interface()
+		}
+	}
+	return map[string]interface{}{"$in": values}, nil
+}
+
+// like returns an operator which matches the field with a regular
+// expression following the SQL LIKE semantics.
+func like(caseInsensitive bool) OperatorFunc {
+	options := "s"
+	if caseInsensitive {
+		options = "is"
+	}
+	return func(value interface{}) (map[string]interface{}, error) {
+		pattern, ok := value.(string)
+		if !ok {
+			pattern = fmt.Sprint(value)
+		}
+		return map[string]interface{}{"$regex": likePattern(pattern), "$options": options}, nil
+	}
+}
+
+// likePattern converts the SQL LIKE pattern into an anchored regular
+// expression. "%" matches any amount of characters and "_" exactly one.
+// A backslash escapes the following character.
+func likePattern(pattern string) string {
+	expression := "^"
+	escaped := false
+	for _, char := range pattern {
+		switch {
+		case escaped:
+			expression += regexp.QuoteMeta(string(char))
+			escaped = false
+		case char == '\\':
+			escaped = true
+		case char == '%':
+			expression += ".*"
+		case char == '_':
+			expression += "."
+		default:
+			expression += regexp.QuoteMeta(string(char))
+		}
+	}
+	return expression + "$"
+}
+
+// NewTranslator returns a translator with operators for all filters
+// defined in the definition package.
+func NewTranslator() *Translator {
+	translator := &Translator{
+		operators: map[string]OperatorFunc{},
+	}
+	translator.RegisterOperator(definition.FilterEq.Identification, comparison("$eq"))
+	translator.RegisterOperator(definition.FilterLt.Identification, comparison("$lt"))
+	translator.RegisterOperator(definition.FilterLte.Identification, comparison("$lte"))
+	translator.RegisterOperator(definition.FilterGt.Identification, comparison("$gt"))
+	translator.RegisterOperator(definition.FilterGte.Identification, comparison("$gte"))
+	translator.RegisterOperator(definition.FilterIn.Identification, in)
+	translator.RegisterOperator(definition.FilterLike.Identification, like(false))
+	translator.RegisterOperator(definition.FilterILike.Identification, like(true))
+	translator.RegisterOperator(definition.FilterIsNull.Identification, nullCheck("$eq"))
+	translator.RegisterOperator(definition.FilterNotNull.Identification, nullCheck("$ne"))
+	return translator
+}
@@ -0,0 +1,116 @@
+package mongo
+
+import (
+	"net/url"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/cbrand/go-filterparams"
+	"github.com/cbrand/go-filterparams/backend/internal/golden"
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+var _ = Suite(&TranslatorTest{})
+
+type TranslatorTest struct {
+	builder *filterparams.QueryBuilder
+}
+
+func (t *TranslatorTest) SetUpTest(c *C) {
+	t.builder = golden.NewBuilder()
+}
+
+func (t *TranslatorTest) translate(c *C, query string) *Query {
+	values, err := url.ParseQuery(query)
+	c.Assert(err, IsNil)
+	queryData, err := t.builder.CreateQuery().Parse(&values)
+	c.Assert(err, IsNil)
+	translated, err := NewTranslator().Translate(queryData)
+	c.Assert(err, IsNil)
+	return translated
+}
+
+func (t *TranslatorTest) TestGolden(c *C) {
+	golden.AddFields(t.builder)
+	for name, query := range map[string]string{
+		"empty":        "",
+		"comparisons":  "filter[param][age][gte]=18&filter[param][age][lt][max]=65&filter[binding]=age%26max",
+		"or":           "filter[param][name][like]=Jo%25&filter[param][name][eq][doe]=Doe&filter[binding]=name|doe",
+		"and_flatten":  "filter[param][name]=a&filter[param][email]=b&filter[param][status]=c&filter[binding]=(name%26email)%26status",
+		"negate":       "filter[param][status][in]=deleted,banned&filter[binding]=!status",
+		"negate_or":    "filter[param][name]=a&filter[param][email]=b&filter[binding]=!(name|email)",
+		"negate_and":   "filter[param][name]=a&filter[param][email]=b&filter[binding]=!(name%26email)",
+		"like":         "filter[param][name][like]=J_hn.%25&filter[param][email][ilike]=%25@EXAMPLE.com&filter[binding]=name%26email",
+		"like_escape":  "filter[param][name][like]=100\\%25",
+		"null_checks":  "filter[param][email][isnull]=&filter[param][status][notnull]=&filter[binding]=email|status",
+		"relationship": "filter[param][tags.label][in]=go,mongo",
+		"sort":         "filter[order]=desc(name)&filter[order]=age&filter[order]=asc(email,nullsfirst)",
+		"sort_nested":  "filter[order]=desc(company.name)",
+	} {
+		translated := t.translate(c, query)
+		golden.Assert(c, name, map[string]interface{}{"filter": translated.Filter, "sort": translated.Sort})
+	}
+}
+
+func (t *TranslatorTest) TestUnsupportedFilter(c *C) {
+	t.builder.EnableFilter(&definition.Filter{Identification: "near"})
+	values := url.Values{"filter[param][location][near]": {"x"}}
+	queryData, err := t.builder.CreateQuery().Parse(&values)
+	c.Assert(err, IsNil)
+	_, err = NewTranslator().Translate(queryData)
+	c.Assert(err, FitsTypeOf, &UnsupportedFilterError{})
+
+	translator := NewTranslator().RegisterOperator("near", func(value interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"$near": value}, nil
+	})
+	translated, err := translator.Translate(queryData)
+	c.Assert(err, IsNil)
+	c.Assert(translated.Filter, DeepEquals, map[string]interface{}{"location": map[string]interface{}{"$near": "x"}})
+}
+
+func (t *TranslatorTest) TestUnsupportedQuantifier(c *C) {
+	tags := definition.NewRelationship("tags", "tags", "id", "user_id").EnableField("label", "")
+	t.builder.AddRelationship(tags.SetQuantifier(definition.QuantifierAll))
+	values := url.Values{"filter[param][tags.label]": {"go"}}
+	queryData, err := t.builder.CreateQuery().Parse(&values)
+	c.Assert(err, IsNil)
+	_, err = NewTranslator().Translate(queryData)
+	c.Assert(err, FitsTypeOf, &UnsupportedQuantifierError{})
+}
+
+func (t *TranslatorTest) TestUnsupportedNulls(c *C) {
+	for _, order := range []string{"asc(name,nullslast)", "desc(name,nullsfirst)"} {
+		values := url.Values{"filter[order]": {order}}
+		queryData, err := t.builder.CreateQuery().Parse(&values)
+		c.Assert(err, IsNil)
+		_, err = NewTranslator().Translate(queryData)
+		c.Assert(err, FitsTypeOf, &UnsupportedNullsError{}, Commentf("%s", order))
+	}
+}
+
+func (t *TranslatorTest) TestCursor(c *C) {
+	t.builder.EnableCursorPagination("id", []byte("0123456789abcdef0123456789abcdef"))
+	c.Assert(t.translate(c, "filter[order]=name").Sort, DeepEquals, []SortEntry{{Key: "name", Value: 1}, {Key: "id", Value: 1}})
+
+	values := url.Values{"filter[order]": {"name"}}
+	query := t.builder.CreateQuery()
+	queryData, err := query.Parse(&values)
+	c.Assert(err, IsNil)
+	cursor, err := query.EncodeCursor(queryData, map[string]interface{}{"name": "doe", "id": 7})
+	c.Assert(err, IsNil)
+	translated := t.translate(c, "filter[order]=name&page[after]="+url.QueryEscape(cursor))
+	c.Assert(translated.Filter, DeepEquals, map[string]interface{}{"$or": []interface{}{
+		map[string]interface{}{"name": map[string]interface{}{"$gt": "doe"}},
+		map[string]interface{}{"$and": []interface{}{
+			map[string]interface{}{"name": map[string]interface{}{"$eq": "doe"}},
+			map[string]interface{}{"id": map[string]interface{}{"$gt": "7"}},
+		}},
+	}})
+}
+
+func (t *TranslatorTest) TestLikePattern(c *C) {
+	c.Assert(likePattern("Jo%"), Equals, "^Jo.*$")
+	c.Assert(likePattern("a_c"), Equals, "^a.c$")
+	c.Assert(likePattern("1+1=2\\%"), Equals, "^1\\+1=2%$")
+	c.Assert(likePattern("a\\_b.c"), Equals, "^a_b\\.c$")
+}
@@ -0,0 +1,405 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/cbrand/go-filterparams"
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+// Binder is used by operators to register arguments of the statement.
+type Binder interface {
+	// Bind adds the value to the arguments and returns the placeholder
+	// which should be used in the statement.
+	Bind(value interface{}) string
+	// Dialect returns the dialect the statement is compiled for.
+	Dialect() Dialect
+}
+
+// OperatorFunc converts one parameter into a SQL condition. The column
+// is already quoted.
+type OperatorFunc func(binder Binder, column string, value interface{}) (string, error)
+
+// UnsupportedFilterError is returned if a parameter uses a filter
+// which has no registered operator.
+type UnsupportedFilterError struct {
+	Filter string
+}
+
+// Error returns the formatted error message.
+func (u *UnsupportedFilterError) Error() string {
+	return fmt.Sprintf("The filter \"%s\" can not be converted to SQL", u.Filter)
+}
+
+// UnresolvedPathError is returned if a parameter or order uses a dotted
+// path like "author.name" without declared relationships, which are
+// required to join the related table.
+type UnresolvedPathError struct {
+	Path string
+}
+
+// Error returns the formatted error message.
+func (u *UnresolvedPathError) Error() string {
+	return fmt.Sprintf("The path \"%s\" can not be converted to SQL without relationships", u.Path)
+}
+
+// ErrNoTable is returned if a parameter filters a relationship but no table
+// has been set on the compiler.
+var ErrNoTable = errors.New("A table is required to filter relationships")
+
+// Clause is the compiled result of a QueryData.
+type Clause struct {
+	// Columns is the projected column list of the sparse fieldset of the
+	// queried resource. It is "*" if no fieldset has been requested.
+	Columns string
+	// Where is the condition of the WHERE clause without the keyword. It
+	// is empty if no filter has been given.
+	Where string
+	// OrderBy is the ORDER BY list without the keyword. It is empty if
+	// no order has been given.
+	OrderBy string
+	// LimitOffset is the LIMIT and OFFSET clause including the keywords.
+	// It is empty if no page has been requested.
+	LimitOffset string
+	// Args are the arguments for the placeholders in Where.
+	Args []interface{}
+}
+
+// SQL returns the WHERE, ORDER BY, LIMIT and OFFSET parts including their
+// keywords.
+func (c *Clause) SQL() string {
+	parts := []string{}
+	if len(c.Where) > 0 {
+		parts = append(parts, "WHERE "+c.Where)
+	}
+	if len(c.OrderBy) > 0 {
+		parts = append(parts, "ORDER BY "+c.OrderBy)
+	}
+	if len(c.LimitOffset) > 0 {
+		parts = append(parts, c.LimitOffset)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Compiler translates parsed query data into SQL fragments.
+type Compiler struct {
+	dialect   Dialect
+	table     string
+	idColumn  string
+	operators map[string]OperatorFunc
+}
+
+// SetTable sets the table of the filtered resource. It is required to
+// correlate the subqueries of relationship filters.
+func (c *Compiler) SetTable(table string) *Compiler {
+	c.table = table
+	return c
+}
+
+// SetIDColumn sets the column of the resource identifier which is always
+// part of the projected columns. Defaults to "id", an empty column isn't
+// added.
+func (c *Compiler) SetIDColumn(column string) *Compiler {
+	c.idColumn = column
+	return c
+}
+
+// RegisterOperator registers the operator for the filter with the given
+// identification. Already registered operators are replaced.
+func (c *Compiler) RegisterOperator(filterName string, operator OperatorFunc) *Compiler {
+	c.operators[filterName] = operator
+	return c
+}
+
+// Compile converts the query data into a parameterized clause.
+func (c *Compiler) Compile(data *filterparams.QueryData) (*Clause, error) {
+	state := &compilation{compiler: c, args: []interface{}{}}
+	clause := &Clause{Columns: c.compileColumns(data.GetFieldset(data.GetResourceType()))}
+	if data.GetFilter() != nil {
+		where, err := state.compileNode(data.GetFilter())
+		if err != nil {
+			return nil, err
+		}
+		clause.Where = where
+	}
+	orderBy, err := state.compileOrders(data.GetOrders())
+	if err != nil {
+		return nil, err
+	}
+	clause.OrderBy = orderBy
+	if page := data.GetPage(); page != nil {
+		clause.LimitOffset = c.dialect.LimitOffset(page.Limit, page.Offset)
+	}
+	clause.Args = state.args
+	return clause, nil
+}
+
+// compileColumns returns the projected columns of the fieldset: the id
+// column, the backend names of the fields and the local keys of the
+// relationships. Every column is only selected once.
+func (c *Compiler) compileColumns(fieldset *filterparams.Fieldset) string {
+	if fieldset == nil {
+		return "*"
+	}
+	columns := []string{}
+	if len(c.idColumn) > 0 {
+		columns = append(columns, c.idColumn)
+	}
+	for _, field := range fieldset.Fields {
+		columns = append(columns, field.BackendName)
+	}
+	for _, relationship := range fieldset.Relationships {
+		columns = append(columns, relationship.LocalKey)
+	}
+	quoted := []string{}
+	seen := map[string]bool{}
+	for _, column := range columns {
+		if seen[column] {
+			continue
+		}
+		seen[column] = true
+		quoted = append(quoted, c.dialect.QuoteIdentifier(column))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// compilation holds the state of one Compile call.
+type compilation struct {
+	compiler *Compiler
+	args     []interface{}
+	// aliases is the amount of table aliases used by subqueries.
+	aliases int
+}
+
+// Bind adds the value to the arguments and returns its placeholder.
+func (s *compilation) Bind(value interface{}) string {
+	s.args = append(s.args, value)
+	return s.compiler.dialect.Placeholder(len(s.args))
+}
+
+// Dialect returns the dialect of the compiler.
+func (s *compilation) Dialect() Dialect {
+	return s.compiler.dialect
+}
+
+// compileOrders returns the ORDER BY list of the given orders.
+func (s *compilation) compileOrders(orders []*definition.Order) (string, error) {
+	dialect := s.compiler.dialect
+	parts := make([]string, len(orders))
+	for index, order := range orders {
+		direction := "ASC"
+		if order.OrderDesc() {
+			direction = "DESC"
+		}
+		column, err := s.compileOrderColumn(order)
+		if err != nil {
+			return "", err
+		}
+		switch order.GetNulls() {
+		case definition.NullsFirst:
+			parts[index] = dialect.OrderNulls(column, direction, true)
+		case definition.NullsLast:
+			parts[index] = dialect.OrderNulls(column, direction, false)
+		default:
+			parts[index] = fmt.Sprintf("%s %s", column, direction)
+		}
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+// compileOrderColumn returns the column of the order. Orders by fields of
+// to-one relationships select the column of the related row with a
+// correlated subquery.
+func (s *compilation) compileOrderColumn(order *definition.Order) (string, error) {
+	dialect := s.compiler.dialect
+	relationships := order.GetRelationships()
+	if len(relationships) == 0 {
+		if len(definition.SplitPath(order.GetOrderBy())) > 1 {
+			return "", &UnresolvedPathError{Path: order.GetOrderBy()}
+		}
+		return dialect.QuoteIdentifier(order.GetBackendName()), nil
+	}
+	if len(s.compiler.table) == 0 {
+		return "", ErrNoTable
+	}
+	aliases := make([]string, len(relationships))
+	for index := range aliases {
+		s.aliases++
+		aliases[index] = dialect.QuoteIdentifier(fmt.Sprintf("r%d", s.aliases))
+	}
+	tables := make([]string, len(relationships))
+	for index, relationship := range relationships {
+		table := fmt.Sprintf("%s AS %s", dialect.QuoteIdentifier(relationship.Table), aliases[index])
+		if index > 0 {
+			table = fmt.Sprintf(
+				"JOIN %s ON %s.%s = %s.%s", table,
+				aliases[index], dialect.QuoteIdentifier(relationship.ForeignKey),
+				aliases[index-1], dialect.QuoteIdentifier(relationship.LocalKey),
+			)
+		}
+		tables[index] = table
+	}
+	return fmt.Sprintf(
+		"(SELECT %s.%s FROM %s WHERE %s.%s = %s.%s)",
+		aliases[len(aliases)-1], dialect.QuoteIdentifier(order.GetBackendName()), strings.Join(tables, " "),
+		aliases[0], dialect.QuoteIdentifier(relationships[0].ForeignKey),
+		dialect.QuoteIdentifier(s.compiler.table), dialect.QuoteIdentifier(relationships[0].LocalKey),
+	), nil
+}
+
+// compileNode converts one node of the filter tree.
+func (s *compilation) compileNode(node interface{}) (string, error) {
+	switch item := node.(type) {
+	case *definition.And:
+		return s.compileLeftRight(&item.LeftRight, "AND")
+	case *definition.Or:
+		return s.compileLeftRight(&item.LeftRight, "OR")
+	case *definition.Negate:
+		negated, err := s.compileNode(item.Negated)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("NOT (%s)", negated), nil
+	case *definition.Parameter:
+		return s.compileParameter(item)
+	}
+	return "", fmt.Errorf("Unexpected node %T in filter", node)
+}
+
+// compileLeftRight joins both sides of the node with the given keyword.
+func (s *compilation) compileLeftRight(node *definition.LeftRight, keyword string) (string, error) {
+	left, err := s.compileNode(node.Left)
+	if err != nil {
+		return "", err
+	}
+	right, err := s.compileNode(node.Right)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("(%s %s %s)", left, keyword, right), nil
+}
+
+// compileParameter applies the operator of the parameter's filter.
+func (s *compilation) compileParameter(parameter *definition.Parameter) (string, error) {
+	if parameter.Filter == nil {
+		return "", &UnsupportedFilterError{}
+	}
+	operator, ok := s.compiler.operators[parameter.Filter.Identification]
+	if !ok {
+		return "", &UnsupportedFilterError{Filter: parameter.Filter.Identification}
+	}
+	if len(parameter.Relationships) > 0 {
+		return s.compileRelationships(parameter, operator)
+	}
+	if len(parameter.GetPath()) > 1 {
+		return "", &UnresolvedPathError{Path: parameter.Name}
+	}
+	column := s.compiler.dialect.QuoteIdentifier(parameter.GetBackendName())
+	return operator(s, column, parameter.Value)
+}
+
+// compileRelationships applies the operator to the field of the last
+// relationship of the parameter. Every relationship results in a
+// correlated EXISTS subquery. Relationships with QuantifierAll require
+// that no related row violates the condition instead.
+func (s *compilation) compileRelationships(parameter *definition.Parameter, operator OperatorFunc) (string, error) {
+	if len(s.compiler.table) == 0 {
+		return "", ErrNoTable
+	}
+	dialect := s.compiler.dialect
+	aliases := make([]string, len(parameter.Relationships))
+	for index := range aliases {
+		s.aliases++
+		aliases[index] = dialect.QuoteIdentifier(fmt.Sprintf("r%d", s.aliases))
+	}
+	last := aliases[len(aliases)-1]
+	condition, err := operator(s, last+"."+dialect.QuoteIdentifier(parameter.GetBackendName()), parameter.Value)
+	if err != nil {
+		return "", err
+	}
+	for index := len(aliases) - 1; index >= 0; index-- {
+		relationship := parameter.Relationships[index]
+		parent := dialect.QuoteIdentifier(s.compiler.table)
+		if index > 0 {
+			parent = aliases[index-1]
+		}
+		join := fmt.Sprintf(
+			"%s.%s = %s.%s",
+			aliases[index], dialect.QuoteIdentifier(relationship.ForeignKey),
+			parent, dialect.QuoteIdentifier(relationship.LocalKey),
+		)
+		table := dialect.QuoteIdentifier(relationship.Table)
+		if relationship.Quantifier == definition.QuantifierAll {
+			condition = fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s AS %s WHERE %s AND NOT (%s))", table, aliases[index], join, condition)
+		} else {
+			condition = fmt.Sprintf("EXISTS (SELECT 1 FROM %s AS %s WHERE %s AND %s)", table, aliases[index], join, condition)
+		}
+	}
+	return condition, nil
+}
+
+// comparison returns an operator which compares the column with the
+// given SQL operator.
+func comparison(sqlOperator string) OperatorFunc {
+	return func(binder Binder, column string, value interface{}) (string, error) {
+		return fmt.Sprintf("%s %s %s", column, sqlOperator, binder.Bind(value)), nil
+	}
+}
+
+// in binds every entry of a slice value. Non slice values are
+// handled as a list with one entry.
+func in(binder Binder, column string, value interface{}) (string, error) {
+	values := []interface{}{value}
+	reflected := reflect.ValueOf(value)
+	if reflected.Kind() == reflect.Slice || reflected.Kind() == reflect.Array {
+		values = make([]interface{}, reflected.Len())
+		for index := range values {
+			values[index] = reflected.Index(index).Interface()
+		}
+	}
+	if len(values) == 0 {
+		return "1 = 0", nil
+	}
+	placeholders := make([]string, len(values))
+	for index, entry := range values {
+		placeholders[index] = binder.Bind(entry)
+	}
+	return fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", ")), nil
+}
+
+// nullCheck returns an operator which checks the column with IS NULL or
+// IS NOT NULL. The value is ignored.
+func nullCheck(keyword string) OperatorFunc {
+	return func(binder Binder, column string, value interface{}) (string, error) {
+		return fmt.Sprintf("%s %s", column, keyword), nil
+	}
+}
+
+// ilike delegates the case insensitive comparison to the dialect.
+func ilike(binder Binder, column string, value interface{}) (string, error) {
+	return binder.Dialect().ILike(column, binder.Bind(value)), nil
+}
+
+// NewCompiler returns a compiler for the given dialect with operators for
+// all filters defined in the definition package.
+func NewCompiler(dialect Dialect) *Compiler {
+	compiler := &Compiler{
+		dialect:   dialect,
+		idColumn:  "id",
+		operators: map[string]OperatorFunc{},
+	}
+	compiler.RegisterOperator(definition.FilterEq.Identification, comparison("="))
+	compiler.RegisterOperator(definition.FilterLt.Identification, comparison("<"))
+	compiler.RegisterOperator(definition.FilterLte.Identification, comparison("<="))
+	compiler.RegisterOperator(definition.FilterGt.Identification, comparison(">"))
+	compiler.RegisterOperator(definition.FilterGte.Identification, comparison(">="))
+	compiler.RegisterOperator(definition.FilterIn.Identification, in)
+	compiler.RegisterOperator(definition.FilterLike.Identification, comparison("LIKE"))
+	compiler.RegisterOperator(definition.FilterILike.Identification, ilike)
+	compiler.RegisterOperator(definition.FilterIsNull.Identification, nullCheck("IS NULL"))
+	compiler.RegisterOperator(definition.FilterNotNull.Identification, nullCheck("IS NOT NULL"))
+	return compiler
+}
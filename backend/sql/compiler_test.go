@@ -0,0 +1,297 @@
+package sql
+
+import (
+	"net/url"
+	"strings"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/cbrand/go-filterparams"
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+var _ = Suite(&CompilerTest{})
+
+type CompilerTest struct {
+	builder *filterparams.QueryBuilder
+	data    *url.Values
+}
+
+func (t *CompilerTest) SetUpTest(c *C) {
+	t.builder = filterparams.NewBuilder()
+	for _, filter := range []*definition.Filter{
+		definition.FilterEq,
+		definition.FilterLt,
+		definition.FilterLte,
+		definition.FilterGt,
+		definition.FilterGte,
+		definition.FilterIn,
+		definition.FilterLike,
+		definition.FilterILike,
+		definition.FilterIsNull,
+		definition.FilterNotNull,
+	} {
+		t.builder.EnableFilter(filter)
+	}
+	t.data = &url.Values{}
+}
+
+func (t *CompilerTest) compile(c *C, dialect Dialect) *Clause {
+	queryData, err := t.builder.CreateQuery().Parse(t.data)
+	c.Assert(err, IsNil)
+	clause, err := NewCompiler(dialect).Compile(queryData)
+	c.Assert(err, IsNil)
+	return clause
+}
+
+func (t *CompilerTest) TestEmpty(c *C) {
+	clause := t.compile(c, Postgres)
+	c.Assert(clause.Where, Equals, "")
+	c.Assert(clause.OrderBy, Equals, "")
+	c.Assert(clause.SQL(), Equals, "")
+	c.Assert(len(clause.Args), Equals, 0)
+}
+
+func (t *CompilerTest) TestComparisons(c *C) {
+	for operation, expected := range map[string]string{
+		"eq":   "\"age\" = $1",
+		"lt":   "\"age\" < $1",
+		"lte":  "\"age\" <= $1",
+		"gt":   "\"age\" > $1",
+		"gte":  "\"age\" >= $1",
+		"like": "\"age\" LIKE $1",
+	} {
+		t.data = &url.Values{}
+		t.data.Set("filter[param][age]["+operation+"]", "3")
+		clause := t.compile(c, Postgres)
+		c.Assert(clause.Where, Equals, expected)
+		c.Assert(clause.Args, DeepEquals, []interface{}{"3"})
+	}
+}
+
+func (t *CompilerTest) TestBinding(c *C) {
+	t.data.Set("filter[param][name][like][a]", "jo%")
+	t.data.Set("filter[param][name][eq][b]", "doe")
+	t.data.Set("filter[param][age][gt][c]", "18")
+	t.data.Set("filter[binding]", "(a|b)&!c")
+	clause := t.compile(c, Postgres)
+	c.Assert(clause.Where, Equals, "((\"name\" LIKE $1 OR \"name\" = $2) AND NOT (\"age\" > $3))")
+	c.Assert(clause.Args, DeepEquals, []interface{}{"jo%", "doe", "18"})
+}
+
+func (t *CompilerTest) TestPlaceholders(c *C) {
+	t.data.Set("filter[param][name][eq][a]", "doe")
+	t.data.Set("filter[param][age][gt][b]", "18")
+	t.data.Set("filter[binding]", "a&b")
+	c.Assert(t.compile(c, MySQL).Where, Equals, "(`name` = ? AND `age` > ?)")
+	c.Assert(t.compile(c, SQLite).Where, Equals, "(\"name\" = ? AND \"age\" > ?)")
+}
+
+func (t *CompilerTest) TestILike(c *C) {
+	t.data.Set("filter[param][name][ilike]", "jo%")
+	c.Assert(t.compile(c, Postgres).Where, Equals, "\"name\" ILIKE $1")
+	c.Assert(t.compile(c, MySQL).Where, Equals, "LOWER(`name`) LIKE LOWER(?)")
+}
+
+func (t *CompilerTest) TestNullChecks(c *C) {
+	t.data.Set("filter[param][name][isnull]", "")
+	t.data.Set("filter[param][email][notnull]", "x")
+	c.Assert(t.compile(c, Postgres).Where, Equals, "(\"email\" IS NOT NULL AND \"name\" IS NULL)")
+	c.Assert(t.compile(c, Postgres).Args, HasLen, 0)
+}
+
+func (t *CompilerTest) TestIn(c *C) {
+	parameter := definition.NewParameter("id")
+	parameter.Name = "id"
+	parameter.Filter = definition.FilterIn
+	parameter.Value = []int{1, 2, 3}
+	clause, err := NewCompiler(Postgres).Compile(filterparams.NewQueryData(parameter, nil))
+	c.Assert(err, IsNil)
+	c.Assert(clause.Where, Equals, "\"id\" IN ($1, $2, $3)")
+	c.Assert(clause.Args, DeepEquals, []interface{}{1, 2, 3})
+}
+
+func (t *CompilerTest) TestInEmpty(c *C) {
+	parameter := definition.NewParameter("id")
+	parameter.Name = "id"
+	parameter.Filter = definition.FilterIn
+	parameter.Value = []string{}
+	clause, err := NewCompiler(Postgres).Compile(filterparams.NewQueryData(parameter, nil))
+	c.Assert(err, IsNil)
+	c.Assert(clause.Where, Equals, "1 = 0")
+}
+
+func (t *CompilerTest) TestOrders(c *C) {
+	t.data.Add("filter[order]", "name")
+	t.data.Add("filter[order]", "desc(age)")
+	clause := t.compile(c, MySQL)
+	c.Assert(clause.OrderBy, Equals, "`name` ASC, `age` DESC")
+	c.Assert(clause.SQL(), Equals, "ORDER BY `name` ASC, `age` DESC")
+}
+
+func (t *CompilerTest) TestQuoteIdentifier(c *C) {
+	c.Assert(Postgres.QuoteIdentifier("we\"ird"), Equals, "\"we\"\"ird\"")
+	c.Assert(MySQL.QuoteIdentifier("we`ird"), Equals, "`we``ird`")
+}
+
+func (t *CompilerTest) TestUnsupportedFilter(c *C) {
+	t.builder.EnableFilter(&definition.Filter{Identification: "near"})
+	t.data.Set("filter[param][location][near]", "x")
+	queryData, err := t.builder.CreateQuery().Parse(t.data)
+	c.Assert(err, IsNil)
+	_, err = NewCompiler(Postgres).Compile(queryData)
+	_, ok := err.(*UnsupportedFilterError)
+	c.Assert(ok, Equals, true)
+}
+
+func (t *CompilerTest) TestRegisterOperator(c *C) {
+	t.builder.EnableFilter(&definition.Filter{Identification: "near"})
+	t.data.Set("filter[param][location][near]", "x")
+	queryData, err := t.builder.CreateQuery().Parse(t.data)
+	c.Assert(err, IsNil)
+	compiler := NewCompiler(Postgres).RegisterOperator("near", func(binder Binder, column string, value interface{}) (string, error) {
+		return "ST_DWithin(" + column + ", " + binder.Bind(value) + ")", nil
+	})
+	clause, err := compiler.Compile(queryData)
+	c.Assert(err, IsNil)
+	c.Assert(clause.Where, Equals, "ST_DWithin(\"location\", $1)")
+}
+
+func (t *CompilerTest) TestBackendNames(c *C) {
+	t.builder.EnableField("name", "user_name")
+	t.data.Set("filter[param][name]", "doe")
+	t.data.Add("filter[order]", "desc(name)")
+	clause := t.compile(c, Postgres)
+	c.Assert(clause.SQL(), Equals, "WHERE \"user_name\" = $1 ORDER BY \"user_name\" DESC")
+}
+
+func (t *CompilerTest) TestInParsed(c *C) {
+	t.data.Set("filter[param][name][in]", "smith,doe")
+	clause := t.compile(c, Postgres)
+	c.Assert(clause.Where, Equals, "\"name\" IN ($1, $2)")
+	c.Assert(clause.Args, DeepEquals, []interface{}{"smith", "doe"})
+}
+
+func (t *CompilerTest) compileTable(c *C, table string) (*Clause, error) {
+	queryData, err := t.builder.CreateQuery().Parse(t.data)
+	c.Assert(err, IsNil)
+	return NewCompiler(Postgres).SetTable(table).Compile(queryData)
+}
+
+func (t *CompilerTest) TestRelationship(c *C) {
+	author := definition.NewRelationship("author", "users", "author_id", "id").EnableField("name", "full_name")
+	t.builder.EnableField("title", "").AddRelationship(author)
+	t.data.Set("filter[param][title]", "Go")
+	t.data.Set("filter[param][author.name][like]", "Jo%")
+	clause, err := t.compileTable(c, "posts")
+	c.Assert(err, IsNil)
+	c.Assert(clause.Where, Equals, "(EXISTS (SELECT 1 FROM \"users\" AS \"r1\" WHERE \"r1\".\"id\" = \"posts\".\"author_id\" AND \"r1\".\"full_name\" LIKE $1) AND \"title\" = $2)")
+	c.Assert(clause.Args, DeepEquals, []interface{}{"Jo%", "Go"})
+}
+
+func (t *CompilerTest) TestRelationshipOrder(c *C) {
+	company := definition.NewRelationship("company", "companies", "company_id", "id").SetToOne(true).EnableField("name", "")
+	author := definition.NewRelationship("author", "users", "author_id", "id").SetToOne(true).
+		EnableField("name", "full_name").
+		AddRelationship(company)
+	t.builder.EnableField("title", "").AddRelationship(author)
+	t.data.Add("filter[order]", "desc(author.name)")
+	t.data.Add("filter[order]", "author.company.name")
+	clause, err := t.compileTable(c, "posts")
+	c.Assert(err, IsNil)
+	c.Assert(clause.OrderBy, Equals, strings.Join([]string{
+		`(SELECT "r1"."full_name" FROM "users" AS "r1" WHERE "r1"."id" = "posts"."author_id") DESC, `,
+		`(SELECT "r3"."name" FROM "users" AS "r2" JOIN "companies" AS "r3" ON "r3"."id" = "r2"."company_id" `,
+		`WHERE "r2"."id" = "posts"."author_id") ASC`,
+	}, ""))
+}
+
+func (t *CompilerTest) TestUnresolvedPath(c *C) {
+	for key, value := range map[string]string{
+		"filter[order]":              "author.name",
+		"filter[param][author.name]": "doe",
+	} {
+		queryData, err := t.builder.CreateQuery().Parse(&url.Values{key: {value}})
+		c.Assert(err, IsNil)
+		_, err = NewCompiler(Postgres).Compile(queryData)
+		c.Assert(err, DeepEquals, &UnresolvedPathError{Path: "author.name"}, Commentf(key))
+	}
+}
+
+func (t *CompilerTest) TestNestedRelationshipAll(c *C) {
+	comments := definition.NewRelationship("comments", "comments", "id", "post_id").SetQuantifier(definition.QuantifierAll)
+	comments.AddRelationship(definition.NewRelationship("author", "users", "author_id", "id").EnableField("name", ""))
+	t.builder.AddRelationship(comments)
+	t.data.Set("filter[param][comments.author.name]", "doe")
+	clause, err := t.compileTable(c, "posts")
+	c.Assert(err, IsNil)
+	c.Assert(clause.Where, Equals, "NOT EXISTS (SELECT 1 FROM \"comments\" AS \"r1\" WHERE \"r1\".\"post_id\" = \"posts\".\"id\" AND NOT ("+
+		"EXISTS (SELECT 1 FROM \"users\" AS \"r2\" WHERE \"r2\".\"id\" = \"r1\".\"author_id\" AND \"r2\".\"name\" = $1)))")
+}
+
+func (t *CompilerTest) TestRelationshipWithoutTable(c *C) {
+	t.builder.AddRelationship(definition.NewRelationship("author", "users", "author_id", "id").EnableField("name", ""))
+	t.data.Set("filter[param][author.name]", "doe")
+	_, err := t.compileTable(c, "")
+	c.Assert(err, Equals, ErrNoTable)
+}
+
+func (t *CompilerTest) TestOrderNulls(c *C) {
+	t.data.Add("filter[order]", "desc(name,nullslast)")
+	t.data.Add("filter[order]", "asc(age,nullsfirst)")
+	for dialect, expected := range map[Dialect]string{
+		Postgres: "\"name\" DESC NULLS LAST, \"age\" ASC NULLS FIRST",
+		SQLite:   "\"name\" DESC NULLS LAST, \"age\" ASC NULLS FIRST",
+		MySQL:    "`name` IS NULL ASC, `name` DESC, `age` IS NULL DESC, `age` ASC",
+	} {
+		c.Assert(t.compile(c, dialect).OrderBy, Equals, expected)
+	}
+}
+
+func (t *CompilerTest) TestLimitOffset(c *C) {
+	t.builder.EnablePagination(0, 0)
+	c.Assert(t.compile(c, Postgres).LimitOffset, Equals, "")
+
+	t.data.Set("page[limit]", "10")
+	t.data.Set("page[offset]", "20")
+	clause := t.compile(c, MySQL)
+	c.Assert(clause.LimitOffset, Equals, "LIMIT 10 OFFSET 20")
+	c.Assert(clause.SQL(), Equals, "LIMIT 10 OFFSET 20")
+
+	t.data.Del("page[limit]")
+	for dialect, expected := range map[Dialect]string{
+		Postgres: "OFFSET 20",
+		SQLite:   "LIMIT -1 OFFSET 20",
+		MySQL:    "LIMIT 18446744073709551615 OFFSET 20",
+	} {
+		c.Assert(t.compile(c, dialect).LimitOffset, Equals, expected)
+	}
+}
+
+func (t *CompilerTest) TestPageWithFilterAndOrder(c *C) {
+	t.builder.EnablePagination(25, 100)
+	t.data.Set("filter[param][name]", "doe")
+	t.data.Set("filter[order]", "name")
+	t.data.Set("page[number]", "2")
+	c.Assert(t.compile(c, Postgres).SQL(), Equals, "WHERE \"name\" = $1 ORDER BY \"name\" ASC LIMIT 25 OFFSET 25")
+}
+
+func (t *CompilerTest) TestColumns(c *C) {
+	t.builder.SetResourceType("articles").
+		EnableField("title", "").
+		EnableField("body", "content").
+		EnableField("id", "").
+		AddRelationship(definition.NewRelationship("author", "people", "author_id", "id"))
+	c.Assert(t.compile(c, Postgres).Columns, Equals, "*")
+
+	t.data.Set("fields[articles]", "body,author,id")
+	c.Assert(t.compile(c, Postgres).Columns, Equals, "\"id\", \"content\", \"author_id\"")
+	c.Assert(t.compile(c, MySQL).Columns, Equals, "`id`, `content`, `author_id`")
+
+	t.data.Set("fields[articles]", "")
+	queryData, err := t.builder.CreateQuery().Parse(t.data)
+	c.Assert(err, IsNil)
+	clause, err := NewCompiler(Postgres).SetIDColumn("article_id").Compile(queryData)
+	c.Assert(err, IsNil)
+	c.Assert(clause.Columns, Equals, "\"article_id\"")
+}
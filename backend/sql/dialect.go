@@ -0,0 +1,157 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect describes the database specific parts of the generated
+// SQL fragments.
+type Dialect interface {
+	// Placeholder returns the placeholder for the argument at the given
+	// position. The position starts with 1.
+	Placeholder(position int) string
+	// QuoteIdentifier quotes the given column name.
+	QuoteIdentifier(identifier string) string
+	// ILike returns the case insensitive LIKE comparison of the given
+	// column against the passed placeholder.
+	ILike(column, placeholder string) string
+	// OrderNulls returns the ORDER BY entry of the column in the given
+	// direction which places null values first or last.
+	OrderNulls(column, direction string, nullsFirst bool) string
+	// LimitOffset returns the LIMIT and OFFSET clause including the
+	// keywords. A limit of 0 doesn't limit the result.
+	LimitOffset(limit, offset int) string
+}
+
+// postgresDialect is the dialect used for PostgreSQL databases.
+type postgresDialect struct{}
+
+// Placeholder returns the numbered placeholder of PostgreSQL.
+func (d *postgresDialect) Placeholder(position int) string {
+	return fmt.Sprintf("$%d", position)
+}
+
+// QuoteIdentifier quotes the identifier with double quotes.
+func (d *postgresDialect) QuoteIdentifier(identifier string) string {
+	return quoteWith(identifier, "\"")
+}
+
+// ILike uses the native ILIKE operator of PostgreSQL.
+func (d *postgresDialect) ILike(column, placeholder string) string {
+	return fmt.Sprintf("%s ILIKE %s", column, placeholder)
+}
+
+// OrderNulls uses the native NULLS FIRST and NULLS LAST options.
+func (d *postgresDialect) OrderNulls(column, direction string, nullsFirst bool) string {
+	return nullsOption(column, direction, nullsFirst)
+}
+
+// LimitOffset omits the parts which aren't required.
+func (d *postgresDialect) LimitOffset(limit, offset int) string {
+	return limitOffset(limit, offset, "")
+}
+
+// mysqlDialect is the dialect used for MySQL and MariaDB databases.
+type mysqlDialect struct{}
+
+// Placeholder returns the question mark placeholder.
+func (d *mysqlDialect) Placeholder(position int) string {
+	return "?"
+}
+
+// QuoteIdentifier quotes the identifier with backticks.
+func (d *mysqlDialect) QuoteIdentifier(identifier string) string {
+	return quoteWith(identifier, "`")
+}
+
+// ILike lowers both sides of the comparison.
+func (d *mysqlDialect) ILike(column, placeholder string) string {
+	return fmt.Sprintf("LOWER(%s) LIKE LOWER(%s)", column, placeholder)
+}
+
+// OrderNulls sorts by a null check in front of the column as MySQL has
+// no NULLS FIRST and NULLS LAST options.
+func (d *mysqlDialect) OrderNulls(column, direction string, nullsFirst bool) string {
+	nullsDirection := "ASC"
+	if nullsFirst {
+		nullsDirection = "DESC"
+	}
+	return fmt.Sprintf("%s IS NULL %s, %s %s", column, nullsDirection, column, direction)
+}
+
+// LimitOffset uses the largest possible limit if only an offset is given
+// as MySQL requires a LIMIT in front of OFFSET.
+func (d *mysqlDialect) LimitOffset(limit, offset int) string {
+	return limitOffset(limit, offset, "18446744073709551615")
+}
+
+// sqliteDialect is the dialect used for SQLite databases.
+type sqliteDialect struct{}
+
+// Placeholder returns the question mark placeholder.
+func (d *sqliteDialect) Placeholder(position int) string {
+	return "?"
+}
+
+// QuoteIdentifier quotes the identifier with double quotes.
+func (d *sqliteDialect) QuoteIdentifier(identifier string) string {
+	return quoteWith(identifier, "\"")
+}
+
+// ILike lowers both sides of the comparison. SQLite's LIKE is only case
+// insensitive for ASCII characters.
+func (d *sqliteDialect) ILike(column, placeholder string) string {
+	return fmt.Sprintf("LOWER(%s) LIKE LOWER(%s)", column, placeholder)
+}
+
+// OrderNulls uses the NULLS FIRST and NULLS LAST options which are
+// supported since SQLite 3.30.
+func (d *sqliteDialect) OrderNulls(column, direction string, nullsFirst bool) string {
+	return nullsOption(column, direction, nullsFirst)
+}
+
+// LimitOffset uses a negative limit if only an offset is given as SQLite
+// requires a LIMIT in front of OFFSET.
+func (d *sqliteDialect) LimitOffset(limit, offset int) string {
+	return limitOffset(limit, offset, "-1")
+}
+
+var (
+	// Postgres is the dialect for PostgreSQL.
+	Postgres Dialect = &postgresDialect{}
+	// MySQL is the dialect for MySQL and MariaDB.
+	MySQL Dialect = &mysqlDialect{}
+	// SQLite is the dialect for SQLite.
+	SQLite Dialect = &sqliteDialect{}
+)
+
+// quoteWith encloses the identifier in the quote and escapes the quote
+// character by doubling it.
+func quoteWith(identifier, quote string) string {
+	return quote + strings.Replace(identifier, quote, quote+quote, -1) + quote
+}
+
+// nullsOption returns the order entry with the standard NULLS FIRST or
+// NULLS LAST option.
+func nullsOption(column, direction string, nullsFirst bool) string {
+	if nullsFirst {
+		return fmt.Sprintf("%s %s NULLS FIRST", column, direction)
+	}
+	return fmt.Sprintf("%s %s NULLS LAST", column, direction)
+}
+
+// limitOffset returns the LIMIT and OFFSET clause. If only an offset is
+// given the unlimited value is used as limit unless it is empty.
+func limitOffset(limit, offset int, unlimited string) string {
+	parts := []string{}
+	if limit > 0 {
+		parts = append(parts, fmt.Sprintf("LIMIT %d", limit))
+	} else if offset > 0 && len(unlimited) > 0 {
+		parts = append(parts, "LIMIT "+unlimited)
+	}
+	if offset > 0 {
+		parts = append(parts, fmt.Sprintf("OFFSET %d", offset))
+	}
+	return strings.Join(parts, " ")
+}
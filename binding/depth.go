@@ -0,0 +1,37 @@
+package binding
+
+// Depth returns the nesting depth of the binding string without parsing it.
+// Every bracket and every negation adds one level, e.g. "a&b" has a depth of
+// 0 and "!(a|b)" a depth of 2. It can be used to reject deeply nested
+// bindings before they are parsed. The parser also recurses once per
+// operator of flat chains like "a&b&c", which the depth doesn't count. These
+// are bounded by the length of the binding instead.
+func Depth(input string) int {
+	depth, maxDepth, negations := 0, 0, 0
+	levels := []int{}
+	for _, char := range input {
+		switch {
+		case char == '!':
+			negations++
+		case char == '(':
+			depth += negations + 1
+			levels = append(levels, negations+1)
+			negations = 0
+		case char == ')':
+			if len(levels) > 0 {
+				depth -= levels[len(levels)-1]
+				levels = levels[:len(levels)-1]
+			}
+			negations = 0
+		case isTermChar(char):
+			if depth+negations > maxDepth {
+				maxDepth = depth + negations
+			}
+			negations = 0
+		}
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+	}
+	return maxDepth
+}
@@ -0,0 +1,123 @@
+package binding
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Position is a position in the binding string.
+type Position struct {
+	// Offset is the byte offset starting with 0.
+	Offset int
+	// Line is the line starting with 1.
+	Line int
+	// Column is the column in characters starting with 1.
+	Column int
+}
+
+// SyntaxError is returned if the binding string can not be parsed.
+type SyntaxError struct {
+	Position
+	// Input is the binding string which has been parsed.
+	Input string
+	// Expected lists the tokens which would have been valid at the
+	// position.
+	Expected []string
+}
+
+// Error returns the formatted error message.
+func (s *SyntaxError) Error() string {
+	message := fmt.Sprintf("Invalid binding at line %d, column %d", s.Line, s.Column)
+	if len(s.Expected) > 0 {
+		message = fmt.Sprintf("%s, expected one of %s", message, strings.Join(s.Expected, ", "))
+	}
+	return message
+}
+
+// expectedNames maps the expectations of the parser to readable names.
+var expectedNames = map[string]string{
+	"[ \\n\\t\\r]":       "",
+	"[^ \\n\\t\\r()&|!]": "parameter",
+	"EOF":                "end of input",
+}
+
+// newSyntaxError converts the errors of the generated parser into a
+// SyntaxError describing the first error.
+func newSyntaxError(input string, err error) error {
+	list, ok := err.(errList)
+	if !ok || len(list) == 0 {
+		return err
+	}
+	parseErr, ok := list[0].(*parserError)
+	if !ok {
+		return err
+	}
+	expected := []string{}
+	for _, entry := range parseErr.expected {
+		name, known := expectedNames[entry]
+		if !known {
+			name = entry
+			if unquoted, unquoteErr := strconv.Unquote(entry); unquoteErr == nil {
+				name = unquoted
+			}
+		}
+		if len(name) > 0 {
+			expected = append(expected, name)
+		}
+	}
+	position := Position{
+		Offset: parseErr.pos.offset,
+		Line:   parseErr.pos.line,
+		Column: parseErr.pos.col,
+	}
+	if nameErr, ok := parseErr.Inner.(*invalidNameError); ok {
+		for _, char := range input[position.Offset : position.Offset+nameErr.leading] {
+			position.advance(char, utf8.RuneLen(char))
+		}
+		expected = []string{"parameter"}
+	}
+	return &SyntaxError{
+		Position: position,
+		Input:    input,
+		Expected: expected,
+	}
+}
+
+// Locate returns the position of the first occurrence of the parameter
+// with the given identification in the binding string. Returns false if
+// the parameter is not referenced.
+func Locate(input, identification string) (Position, bool) {
+	position := Position{Line: 1, Column: 1}
+	for position.Offset < len(input) {
+		char, size := utf8.DecodeRuneInString(input[position.Offset:])
+		if !isTermChar(char) {
+			position.advance(char, size)
+			continue
+		}
+		start := position
+		for position.Offset < len(input) {
+			char, size = utf8.DecodeRuneInString(input[position.Offset:])
+			if !isTermChar(char) {
+				break
+			}
+			position.advance(char, size)
+		}
+		if input[start.Offset:position.Offset] == identification {
+			return start, true
+		}
+	}
+	return Position{}, false
+}
+
+// advance moves the position behind the given character.
+func (p *Position) advance(char rune, size int) {
+	p.Offset += size
+	if char == '\n' {
+		p.Line++
+		p.Column = 1
+	} else {
+		p.Column++
+	}
+}
@@ -0,0 +1,63 @@
+package binding
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+// nameCharsKey is the key of the name character class in the global store
+// of the parser.
+const nameCharsKey = "nameChars"
+
+// IsNameChar is the default character class of names. It accepts unicode
+// letters and digits, "_" and "-".
+func IsNameChar(char rune) bool {
+	return unicode.IsLetter(char) || unicode.IsDigit(char) || char == '_' || char == '-'
+}
+
+// ValidName returns if the name consists of non empty segments separated by
+// dots which only contain characters accepted by isNameChar. If isNameChar
+// is nil IsNameChar is used.
+func ValidName(name string, isNameChar func(rune) bool) bool {
+	if isNameChar == nil {
+		isNameChar = IsNameChar
+	}
+	for _, segment := range definition.SplitPath(name) {
+		if len(segment) == 0 {
+			return false
+		}
+		for _, char := range segment {
+			if !isNameChar(char) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// NameChars returns an option which configures the characters allowed in
+// the parameter names of the binding. Defaults to IsNameChar.
+func NameChars(isNameChar func(rune) bool) Option {
+	return GlobalStore(nameCharsKey, isNameChar)
+}
+
+// invalidNameError is returned by the parser if a term contains characters
+// which are not allowed in names.
+type invalidNameError struct {
+	name string
+	// leading is the amount of whitespace bytes in front of the name.
+	leading int
+}
+
+// Error returns the formatted error message.
+func (i *invalidNameError) Error() string {
+	return "Invalid name \"" + i.name + "\""
+}
+
+// isTermChar returns if the character may be part of a term in the binding.
+// These are all characters except whitespace, brackets and operators.
+func isTermChar(char rune) bool {
+	return !strings.ContainsRune(" \n\t\r()&|!", char)
+}
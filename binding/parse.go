@@ -1,7 +1,11 @@
 package binding
 
 // ParseString parses the given query order parameter and returns a parameter
-// entry.
+// entry. If the data is invalid a *SyntaxError is returned.
 func ParseString(data string, opts ...Option) (interface{}, error) {
-	return Parse("data", []byte(data), opts...)
+	result, err := Parse("data", []byte(data), opts...)
+	if err != nil {
+		return nil, newSyntaxError(data, err)
+	}
+	return result, nil
 }
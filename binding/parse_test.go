@@ -170,3 +170,91 @@ func (t *ParseTest) TestParseNegationBracketAnd(c *C) {
 	c.Assert(ok, Equals, true)
 	c.Assert(rightDefinition.Identification, Equals, "item3")
 }
+
+func (t *ParseTest) syntaxError(c *C, data string) *SyntaxError {
+	_, err := ParseString(data)
+	syntaxErr, ok := err.(*SyntaxError)
+	c.Assert(ok, Equals, true)
+	c.Assert(syntaxErr.Input, Equals, data)
+	return syntaxErr
+}
+
+func (t *ParseTest) TestSyntaxError(c *C) {
+	syntaxErr := t.syntaxError(c, "a & )b")
+	c.Assert(syntaxErr.Position, Equals, Position{Offset: 4, Line: 1, Column: 5})
+	c.Assert(syntaxErr.Expected, DeepEquals, []string{"!", "(", "parameter"})
+	c.Assert(syntaxErr.Error(), Equals, "Invalid binding at line 1, column 5, expected one of !, (, parameter")
+}
+
+func (t *ParseTest) TestSyntaxErrorMultiline(c *C) {
+	syntaxErr := t.syntaxError(c, "a\n& (b")
+	c.Assert(syntaxErr.Position, Equals, Position{Offset: 6, Line: 2, Column: 5})
+	c.Assert(syntaxErr.Expected, DeepEquals, []string{"&", ")", "|", "parameter"})
+}
+
+func (t *ParseTest) TestSyntaxErrorEndOfInput(c *C) {
+	syntaxErr := t.syntaxError(c, "a b")
+	c.Assert(syntaxErr.Expected, DeepEquals, []string{"&", "|", "end of input"})
+}
+
+func (t *ParseTest) TestLocate(c *C) {
+	position, ok := Locate("ab & (a |\n !abc)", "abc")
+	c.Assert(ok, Equals, true)
+	c.Assert(position, Equals, Position{Offset: 12, Line: 2, Column: 3})
+
+	position, ok = Locate("ab & (a | !abc)", "a")
+	c.Assert(ok, Equals, true)
+	c.Assert(position, Equals, Position{Offset: 6, Line: 1, Column: 7})
+
+	_, ok = Locate("ab & abc", "b")
+	c.Assert(ok, Equals, false)
+}
+
+func (t *ParseTest) TestDepth(c *C) {
+	c.Assert(Depth("a&b|c"), Equals, 0)
+	c.Assert(Depth("!a"), Equals, 1)
+	c.Assert(Depth("!!a&b"), Equals, 2)
+	c.Assert(Depth("(a&b)|(c&d)"), Equals, 1)
+	c.Assert(Depth("!(a|(b&!c))"), Equals, 4)
+	c.Assert(Depth("((((a))))"), Equals, 4)
+	c.Assert(Depth("a)&(b"), Equals, 1)
+}
+
+func (t *ParseTest) TestParseUnicodeAndDottedNames(c *C) {
+	data := t.parse(c, "straße & address.city0")
+	and := data.(*definition.And)
+	c.Assert(and.Left.(*definition.Parameter).Identification, Equals, "straße")
+	c.Assert(and.Right.(*definition.Parameter).Identification, Equals, "address.city0")
+}
+
+func (t *ParseTest) TestInvalidName(c *C) {
+	syntaxErr := t.syntaxError(c, "a &\n  b$c")
+	c.Assert(syntaxErr.Position, Equals, Position{Offset: 6, Line: 2, Column: 3})
+	c.Assert(syntaxErr.Expected, DeepEquals, []string{"parameter"})
+
+	for _, data := range []string{"a..b", ".a", "a."} {
+		_, err := ParseString(data)
+		c.Assert(err, FitsTypeOf, &SyntaxError{}, Commentf("%s", data))
+	}
+}
+
+func (t *ParseTest) TestNameChars(c *C) {
+	isNameChar := func(char rune) bool {
+		return IsNameChar(char) || char == ':'
+	}
+	data, err := ParseString("ns:a | b", NameChars(isNameChar))
+	c.Assert(err, IsNil)
+	c.Assert(data.(*definition.Or).Left.(*definition.Parameter).Identification, Equals, "ns:a")
+
+	_, err = ParseString("ns:a | b")
+	c.Assert(err, NotNil)
+}
+
+func (t *ParseTest) TestValidName(c *C) {
+	c.Assert(ValidName("name_0-1", nil), Equals, true)
+	c.Assert(ValidName("név", nil), Equals, true)
+	c.Assert(ValidName("author.name", nil), Equals, true)
+	c.Assert(ValidName("", nil), Equals, false)
+	c.Assert(ValidName("a b", nil), Equals, false)
+	c.Assert(ValidName("author..name", nil), Equals, false)
+}
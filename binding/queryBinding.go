@@ -348,11 +348,10 @@ var g = &grammar{
 							pos: position{line: 42, col: 11, offset: 732},
 							expr: &charClassMatcher{
 								pos:        position{line: 42, col: 11, offset: 732},
-								val:        "[a-zA-Z0-9_\\\\-]",
-								chars:      []rune{'_', '\\', '-'},
-								ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
+								val:        "[^ \\n\\t\\r()&|!]",
+								chars:      []rune{' ', '\n', '\t', '\r', '(', ')', '&', '|', '!'},
 								ignoreCase: false,
-								inverted:   false,
+								inverted:   true,
 							},
 						},
 						&ruleRefExpr{
@@ -366,11 +365,11 @@ var g = &grammar{
 		{
 			name:        "_",
 			displayName: "\"whitespace\"",
-			pos:         position{line: 47, col: 1, offset: 844},
+			pos:         position{line: 52, col: 1, offset: 1071},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 47, col: 19, offset: 862},
+				pos: position{line: 52, col: 19, offset: 1089},
 				expr: &charClassMatcher{
-					pos:        position{line: 47, col: 19, offset: 862},
+					pos:        position{line: 52, col: 19, offset: 1089},
 					val:        "[ \\n\\t\\r]",
 					chars:      []rune{' ', '\n', '\t', '\r'},
 					ignoreCase: false,
@@ -380,11 +379,11 @@ var g = &grammar{
 		},
 		{
 			name: "EOF",
-			pos:  position{line: 49, col: 1, offset: 874},
+			pos:  position{line: 54, col: 1, offset: 1101},
 			expr: &notExpr{
-				pos: position{line: 49, col: 8, offset: 881},
+				pos: position{line: 54, col: 8, offset: 1108},
 				expr: &anyMatcher{
-					line: 49, col: 9, offset: 882,
+					line: 54, col: 9, offset: 1109,
 				},
 			},
 		},
@@ -458,7 +457,12 @@ func (p *parser) callonNegateTerm1() (interface{}, error) {
 }
 
 func (c *current) onTerm1() (interface{}, error) {
-	name := strings.TrimSpace(string(c.text))
+	text := string(c.text)
+	name := strings.TrimSpace(text)
+	isNameChar, _ := c.globalStore[nameCharsKey].(func(rune) bool)
+	if !ValidName(name, isNameChar) {
+		return nil, &invalidNameError{name: name, leading: len(text) - len(strings.TrimLeft(text, " \n\t\r"))}
+	}
 	return definition.NewParameter(name), nil
 }
 
@@ -526,18 +530,17 @@ func Entrypoint(ruleName string) Option {
 //
 // Example usage:
 //
-//     input := "input"
-//     stats := Stats{}
-//     _, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
-//     if err != nil {
-//         log.Panicln(err)
-//     }
-//     b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
-//     if err != nil {
-//         log.Panicln(err)
-//     }
-//     fmt.Println(string(b))
-//
+//	input := "input"
+//	stats := Stats{}
+//	_, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	fmt.Println(string(b))
 func Statistics(stats *Stats, choiceNoMatch string) Option {
 	return func(p *parser) Option {
 		oldStats := p.Stats
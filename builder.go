@@ -11,7 +11,21 @@ import (
 // query parameters.
 type QueryBuilder struct {
 	filters          []*definition.Filter
+	fields           []*definition.Field
+	fieldFilters     map[string][]*definition.Filter
+	fieldTypes       map[string]definition.FieldType
+	relationships    []*definition.Relationship
 	defaultOperation string
+	listSeparator    rune
+	listEscape       rune
+	collectErrors    bool
+	bindingShape     BindingShape
+	limits           Limits
+	isNameChar       func(rune) bool
+	sortParameter    string
+	pagination       *Pagination
+	keyset           *keyset
+	resourceType     string
 }
 
 // EnableFilter allows a filter to be registered against the query builder.
@@ -52,6 +66,108 @@ func (q *QueryBuilder) filterIndexOf(filterName string) int {
 	return -1
 }
 
+// EnableField registers a field with its name in the query and the name
+// in the backend. As soon as one field is registered only registered
+// fields may be filtered and ordered by.
+func (q *QueryBuilder) EnableField(name, backendName string) *QueryBuilder {
+	return q.AddField(definition.NewField(name, backendName))
+}
+
+// AddField registers the given field definition. A previously registered
+// field with the same name is replaced.
+func (q *QueryBuilder) AddField(field *definition.Field) *QueryBuilder {
+	index := q.fieldIndexOf(field.Name)
+	if index == -1 {
+		q.fields = append(q.fields, field)
+	} else {
+		q.fields[index] = field
+	}
+	return q
+}
+
+// EnableFieldFilter restricts the field with the given name to the passed
+// filters. These override the filters registered with EnableFilter for
+// the field. If the field is registered the filters are added to it,
+// otherwise they are kept by name without restricting the allowed fields.
+func (q *QueryBuilder) EnableFieldFilter(name string, filters ...*definition.Filter) *QueryBuilder {
+	if field, err := q.GetField(name); err == nil {
+		for _, filter := range filters {
+			field.EnableFilter(filter)
+		}
+		return q
+	}
+	if q.fieldFilters == nil {
+		q.fieldFilters = map[string][]*definition.Filter{}
+	}
+	q.fieldFilters[name] = append(q.fieldFilters[name], filters...)
+	return q
+}
+
+// SetFieldType sets the type the values of the field with the given name are
+// converted to. If the field is registered the type is set on it,
+// otherwise it is kept by name without restricting the allowed fields.
+func (q *QueryBuilder) SetFieldType(name string, fieldType definition.FieldType) *QueryBuilder {
+	if field, err := q.GetField(name); err == nil {
+		field.SetType(fieldType)
+		return q
+	}
+	if q.fieldTypes == nil {
+		q.fieldTypes = map[string]definition.FieldType{}
+	}
+	q.fieldTypes[name] = fieldType
+	return q
+}
+
+// RemoveFields removes all fields and thus allows all parameter names.
+func (q *QueryBuilder) RemoveFields() *QueryBuilder {
+	q.fields = []*definition.Field{}
+	return q
+}
+
+// HasField returns if the field with the given name is registered.
+func (q *QueryBuilder) HasField(name string) bool {
+	return q.fieldIndexOf(name) != -1
+}
+
+// GetField returns the field with the given name if it exists. Returns an error
+// if none is present.
+func (q *QueryBuilder) GetField(name string) (*definition.Field, error) {
+	index := q.fieldIndexOf(name)
+	if index == -1 {
+		return nil, fmt.Errorf("Field %s does not exist.", name)
+	}
+	return q.fields[index], nil
+}
+
+// fieldIndexOf returns the index of the field with the given name or -1 if none exists.
+func (q *QueryBuilder) fieldIndexOf(name string) int {
+	for index, field := range q.fields {
+		if field.Name == name {
+			return index
+		}
+	}
+	return -1
+}
+
+// AddRelationship declares a related resource whose fields may be filtered
+// with a dotted path like "author.name". A previously registered
+// relationship with the same name is replaced. As soon as a relationship or
+// field is registered only registered fields may be filtered.
+func (q *QueryBuilder) AddRelationship(relationship *definition.Relationship) *QueryBuilder {
+	q.relationships = definition.AddRelationship(q.relationships, relationship)
+	return q
+}
+
+// GetRelationship returns the relationship with the given name if it exists.
+// Returns an error if none is present.
+func (q *QueryBuilder) GetRelationship(name string) (*definition.Relationship, error) {
+	relationship := definition.GetRelationship(q.relationships, name)
+	if relationship == nil {
+		return nil, fmt.Errorf("Relationship %s does not exist.", name)
+	}
+	return relationship, nil
+}
+
 // SetDefaultOperation takes the name of the operation which is used for the parameters
 // if it is not provided.
 func (q *QueryBuilder) SetDefaultOperation(defaultOperation string) *QueryBuilder {
@@ -59,10 +175,150 @@ func (q *QueryBuilder) SetDefaultOperation(defaultOperation string) *QueryBuilde
 	return q
 }
 
+// SetListSeparator sets the character which separates the entries of list
+// values like the ones of the "in" filter. Defaults to ",".
+func (q *QueryBuilder) SetListSeparator(separator rune) *QueryBuilder {
+	q.listSeparator = separator
+	return q
+}
+
+// SetListEscape sets the character which escapes the separator in list
+// values. Defaults to a backslash.
+func (q *QueryBuilder) SetListEscape(escape rune) *QueryBuilder {
+	q.listEscape = escape
+	return q
+}
+
+// SetCollectErrors configures if the query should validate all parameters
+// instead of returning on the first error. The errors are returned as
+// ParseErrors.
+func (q *QueryBuilder) SetCollectErrors(collect bool) *QueryBuilder {
+	q.collectErrors = collect
+	return q
+}
+
+// SetDefaultBindingShape sets the shape of the tree which is created if no
+// filter[binding] has been passed. The parameters are always ordered by
+// their alias. Defaults to BindingRightDeep.
+func (q *QueryBuilder) SetDefaultBindingShape(shape BindingShape) *QueryBuilder {
+	q.bindingShape = shape
+	return q
+}
+
+// SetNameChars configures the characters which are allowed in the names and
+// aliases of parameters and in orders. Names may additionally be dotted
+// relationship paths like "address.city". Defaults to binding.IsNameChar
+// which accepts unicode letters and digits, "_" and "-".
+func (q *QueryBuilder) SetNameChars(isNameChar func(rune) bool) *QueryBuilder {
+	q.isNameChar = isNameChar
+	return q
+}
+
+// SetSortParameter enables parsing orders from a JSON:API sort parameter
+// with the given name, e.g. "sort" for "sort=-created,name". A leading "-"
+// marks a descending order. These orders are applied after the ones of
+// filter[order]. An empty name disables the parameter, which is the default.
+func (q *QueryBuilder) SetSortParameter(name string) *QueryBuilder {
+	q.sortParameter = name
+	return q
+}
+
+// EnablePagination enables parsing page[number] and page[size] or
+// page[offset] and page[limit]. Requests without a page size receive pages
+// of defaultSize entries, larger sizes than maxSize are rejected. A value
+// of 0 disables the respective setting.
+func (q *QueryBuilder) EnablePagination(defaultSize, maxSize int) *QueryBuilder {
+	q.pagination = &Pagination{DefaultSize: defaultSize, MaxSize: maxSize}
+	return q
+}
+
+// DisablePagination ignores the page parameters, which is the default.
+func (q *QueryBuilder) DisablePagination() *QueryBuilder {
+	q.pagination = nil
+	return q
+}
+
+// EnableCursorPagination enables the page[after] and page[before] cursors
+// created by Query.EncodeCursor. An ascending order by the unique
+// tiebreaker field, e.g. "id", is appended to the orders of every query
+// which doesn't order by it yet. The cursors are signed with the secret to
+// detect tampering. It panics if the secret is shorter than
+// MinCursorSecretLength bytes.
+func (q *QueryBuilder) EnableCursorPagination(tiebreaker string, secret []byte) *QueryBuilder {
+	if len(secret) < MinCursorSecretLength {
+		panic(fmt.Sprintf("The cursor secret must have at least %d bytes", MinCursorSecretLength))
+	}
+	q.keyset = &keyset{tiebreaker: tiebreaker, secret: secret}
+	return q
+}
+
+// SetResourceType sets the JSON:API type of the queried resource and
+// enables parsing sparse fieldsets with fields[type] and relationship
+// paths with include. Both are validated against the registered fields
+// and relationships. An empty type disables them, which is the default.
+func (q *QueryBuilder) SetResourceType(resourceType string) *QueryBuilder {
+	q.resourceType = resourceType
+	return q
+}
+
+// SetLimits replaces all complexity limits of the query.
+func (q *QueryBuilder) SetLimits(limits Limits) *QueryBuilder {
+	q.limits = limits
+	return q
+}
+
+// SetMaxParameters limits the amount of filter[param] keys. A value of 0
+// disables the limit.
+func (q *QueryBuilder) SetMaxParameters(max int) *QueryBuilder {
+	q.limits.MaxParameters = max
+	return q
+}
+
+// SetMaxBindingDepth limits the nesting of brackets and negations in the
+// filter[binding]. A value of 0 disables the limit.
+func (q *QueryBuilder) SetMaxBindingDepth(max int) *QueryBuilder {
+	q.limits.MaxBindingDepth = max
+	return q
+}
+
+// SetMaxBindingLength limits the length of the filter[binding]. A value of
+// 0 disables the limit.
+func (q *QueryBuilder) SetMaxBindingLength(max int) *QueryBuilder {
+	q.limits.MaxBindingLength = max
+	return q
+}
+
+// SetMaxOrders limits the amount of filter[order] entries. A value of 0
+// disables the limit.
+func (q *QueryBuilder) SetMaxOrders(max int) *QueryBuilder {
+	q.limits.MaxOrders = max
+	return q
+}
+
+// SetMaxListSize limits the amount of values of list parameters like the
+// ones of the "in" filter. A value of 0 disables the limit.
+func (q *QueryBuilder) SetMaxListSize(max int) *QueryBuilder {
+	q.limits.MaxListSize = max
+	return q
+}
+
 // CreateQuery initializes a new Query and returns it.
 func (q *QueryBuilder) CreateQuery() *Query {
 	query := newQuery(q.filters)
 	query.setDefaultOperation(q.defaultOperation)
+	query.setFields(q.fields)
+	query.setFieldFilters(q.fieldFilters)
+	query.setFieldTypes(q.fieldTypes)
+	query.setRelationships(q.relationships)
+	query.setListFormat(q.listSeparator, q.listEscape)
+	query.setCollectErrors(q.collectErrors)
+	query.setBindingShape(q.bindingShape)
+	query.setLimits(q.limits)
+	query.setNameChars(q.isNameChar)
+	query.setSortParameter(q.sortParameter)
+	query.setPagination(q.pagination)
+	query.setKeyset(q.keyset)
+	query.setResourceType(q.resourceType)
 	return query
 }
 
@@ -71,6 +327,10 @@ func (q *QueryBuilder) CreateQuery() *Query {
 func NewBuilder() *QueryBuilder {
 	queryBuilder := &QueryBuilder{
 		filters: []*definition.Filter{},
+		fields:  []*definition.Field{},
+		fieldFilters: map[string][]*definition.Filter{},
+		fieldTypes: map[string]definition.FieldType{},
+		relationships: []*definition.Relationship{},
 	}
 	return queryBuilder
 }
@@ -47,3 +47,64 @@ func (t *BuilderTest) TestDefaultOrder(c *C) {
 		"lte",
 	)
 }
+
+func (t *BuilderTest) TestEnableField(c *C) {
+	t.builder.EnableField("name", "user_name")
+	c.Assert(t.builder.HasField("name"), Equals, true)
+	field, err := t.builder.GetField("name")
+	c.Assert(err, IsNil)
+	c.Assert(field.BackendName, Equals, "user_name")
+}
+
+func (t *BuilderTest) TestEnableFieldDefaultBackendName(c *C) {
+	t.builder.EnableField("name", "")
+	field, err := t.builder.GetField("name")
+	c.Assert(err, IsNil)
+	c.Assert(field.BackendName, Equals, "name")
+}
+
+func (t *BuilderTest) TestGetFieldNegative(c *C) {
+	t.builder.EnableField("name", "user_name")
+	field, err := t.builder.GetField("email")
+	c.Assert(err, NotNil)
+	c.Assert(field, IsNil)
+}
+
+func (t *BuilderTest) TestEnableFieldFilter(c *C) {
+	t.builder.EnableField("created_at", "created")
+	t.builder.EnableFieldFilter("created_at", definition.FilterLt, definition.FilterGt)
+	field, err := t.builder.GetField("created_at")
+	c.Assert(err, IsNil)
+	c.Assert(field.BackendName, Equals, "created")
+	c.Assert(field.Filters, DeepEquals, []*definition.Filter{definition.FilterLt, definition.FilterGt})
+}
+
+func (t *BuilderTest) TestEnableFieldFilterKeepsFieldsUnrestricted(c *C) {
+	t.builder.EnableFieldFilter("email", definition.FilterEq)
+	c.Assert(t.builder.HasField("email"), Equals, false)
+}
+
+func (t *BuilderTest) TestEnableFieldFilterZeroValue(c *C) {
+	builder := &QueryBuilder{}
+	builder.EnableFieldFilter("email", definition.FilterEq)
+	c.Assert(builder.fieldFilters["email"], DeepEquals, []*definition.Filter{definition.FilterEq})
+}
+
+func (t *BuilderTest) TestSetFieldType(c *C) {
+	t.builder.EnableField("age", "")
+	t.builder.SetFieldType("age", definition.TypeInt)
+	field, err := t.builder.GetField("age")
+	c.Assert(err, IsNil)
+	c.Assert(field.Type, Equals, definition.TypeInt)
+}
+
+func (t *BuilderTest) TestSetFieldTypeZeroValue(c *C) {
+	builder := &QueryBuilder{}
+	builder.SetFieldType("age", definition.TypeInt)
+	c.Assert(builder.fieldTypes["age"], Equals, definition.TypeInt)
+}
+
+func (t *BuilderTest) TestSetFieldTypeKeepsFieldsUnrestricted(c *C) {
+	t.builder.SetFieldType("age", definition.TypeInt)
+	c.Assert(t.builder.HasField("age"), Equals, false)
+}
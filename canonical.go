@@ -0,0 +1,90 @@
+package filterparams
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+// Canonical returns equivalent query data in canonical form. The filter is
+// canonicalized with definition.Canonicalize and orders by a field which
+// has already been ordered by are removed as they have no effect. The
+// cursor is kept as it is.
+func (q *QueryData) Canonical() (*QueryData, error) {
+	var filter interface{}
+	if node, ok := definition.AsNode(q.filter); ok {
+		canonical, err := definition.Canonicalize(node)
+		if err != nil {
+			return nil, err
+		}
+		filter = canonical
+	}
+	orders := []*definition.Order{}
+	seen := map[string]bool{}
+	for _, order := range q.GetOrders() {
+		if seen[order.GetOrderBy()] {
+			continue
+		}
+		seen[order.GetOrderBy()] = true
+		orders = append(orders, order)
+	}
+	canonical := NewQueryData(filter, orders).SetPage(q.GetPage())
+	canonical.resourceType = q.resourceType
+	canonical.fieldsets = q.fieldsets
+	canonical.includes = q.includes
+	canonical.cursor = q.cursor
+	return canonical, nil
+}
+
+// Fingerprint returns a stable hash of the canonical form of the query
+// data. Equivalent queries, e.g. with differently ordered or aliased
+// parameters, have the same fingerprint.
+func (q *QueryData) Fingerprint() (string, error) {
+	canonical, err := q.Canonical()
+	if err != nil {
+		return "", err
+	}
+	filter := "nil"
+	if node, ok := definition.AsNode(canonical.filter); ok {
+		filter = definition.Describe(node)
+	}
+	forwardOrders := canonical.forwardOrders()
+	orders := make([]string, len(forwardOrders))
+	for index, order := range forwardOrders {
+		orders[index] = encodeOrder(order)
+	}
+	description := "filter:" + filter + "\norder:" + strings.Join(orders, ",")
+	if cursor := canonical.GetCursor(); cursor != nil {
+		direction := "after"
+		if cursor.Before {
+			direction = "before"
+		}
+		description += "\n" + direction + ":" + definition.Describe(cursor.Predicate)
+	}
+	if page := canonical.GetPage(); page != nil {
+		description += fmt.Sprintf("\npage:%d,%d", page.Offset, page.Limit)
+	}
+	if fieldsets := canonical.GetFieldsets(); len(fieldsets) > 0 {
+		resourceTypes := make([]string, 0, len(fieldsets))
+		for resourceType := range fieldsets {
+			resourceTypes = append(resourceTypes, resourceType)
+		}
+		sort.Strings(resourceTypes)
+		for _, resourceType := range resourceTypes {
+			names := fieldsets[resourceType].Names()
+			sort.Strings(names)
+			description += "\nfields[" + resourceType + "]:" + strings.Join(names, ",")
+		}
+	}
+	if includes := canonical.GetIncludes(); len(includes) > 0 {
+		sorted := append([]string{}, includes...)
+		sort.Strings(sorted)
+		description += "\ninclude:" + strings.Join(sorted, ",")
+	}
+	hash := sha256.Sum256([]byte(description))
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,110 @@
+package filterparams
+
+import (
+	"net/url"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+var _ = Suite(&CanonicalTest{})
+
+type CanonicalTest struct {
+	builder *QueryBuilder
+}
+
+func (t *CanonicalTest) SetUpTest(c *C) {
+	t.builder = NewBuilder()
+	t.builder.EnableFilter(definition.FilterEq)
+	t.builder.EnableFilter(definition.FilterLike)
+}
+
+func (t *CanonicalTest) fingerprint(c *C, values url.Values) string {
+	queryData, err := t.builder.CreateQuery().Parse(&values)
+	c.Assert(err, IsNil)
+	fingerprint, err := queryData.Fingerprint()
+	c.Assert(err, IsNil)
+	return fingerprint
+}
+
+func (t *CanonicalTest) TestEquivalentQueries(c *C) {
+	first := t.fingerprint(c, url.Values{
+		"filter[param][name][like][a]": {"jo%"},
+		"filter[param][age][eq][b]":    {"3"},
+		"filter[param][city][eq][c]":   {"Berlin"},
+		"filter[binding]":              {"(a&b)|!!c"},
+		"filter[order]":                {"name", "desc(age)", "desc(name)"},
+	})
+	second := t.fingerprint(c, url.Values{
+		"filter[param][name][like][x]": {"jo%"},
+		"filter[param][age][eq][y]":    {"3"},
+		"filter[param][city][eq][z]":   {"Berlin"},
+		"filter[binding]":              {"z|(y&x)"},
+		"filter[order]":                {"name", "desc(age)"},
+	})
+	c.Assert(first, Equals, second)
+	c.Assert(len(first), Equals, 64)
+}
+
+func (t *CanonicalTest) TestDifferentQueries(c *C) {
+	base := t.fingerprint(c, url.Values{"filter[param][name][like]": {"jo%"}})
+	c.Assert(t.fingerprint(c, url.Values{"filter[param][name][eq]": {"jo%"}}), Not(Equals), base)
+	c.Assert(t.fingerprint(c, url.Values{"filter[param][name][like]": {"ja%"}}), Not(Equals), base)
+	c.Assert(t.fingerprint(c, url.Values{
+		"filter[param][name][like]": {"jo%"},
+		"filter[order]":             {"name"},
+	}), Not(Equals), base)
+	c.Assert(t.fingerprint(c, url.Values{}), Not(Equals), base)
+}
+
+func (t *CanonicalTest) TestPageFingerprint(c *C) {
+	base := t.fingerprint(c, url.Values{})
+	t.builder.EnablePagination(10, 0)
+	first := t.fingerprint(c, url.Values{"page[number]": {"2"}})
+	c.Assert(first, Not(Equals), base)
+	c.Assert(t.fingerprint(c, url.Values{"page[offset]": {"10"}}), Equals, first)
+	c.Assert(t.fingerprint(c, url.Values{"page[offset]": {"20"}}), Not(Equals), first)
+}
+
+func (t *CanonicalTest) TestCanonical(c *C) {
+	values := url.Values{
+		"filter[param][name][like]": {"jo%"},
+		"filter[binding]":           {"!!name"},
+		"filter[order]":             {"name", "desc(name)"},
+	}
+	queryData, err := t.builder.CreateQuery().Parse(&values)
+	c.Assert(err, IsNil)
+	canonical, err := queryData.Canonical()
+	c.Assert(err, IsNil)
+	_, ok := canonical.GetFilter().(*definition.Parameter)
+	c.Assert(ok, Equals, true)
+	c.Assert(len(canonical.GetOrders()), Equals, 1)
+	c.Assert(canonical.GetOrders()[0].OrderDesc(), Equals, false)
+}
+
+func (t *CanonicalTest) TestCursor(c *C) {
+	t.builder.EnableCursorPagination("id", []byte("0123456789abcdef0123456789abcdef"))
+	values := url.Values{"filter[order]": {"name"}}
+	query := t.builder.CreateQuery()
+	queryData, err := query.Parse(&values)
+	c.Assert(err, IsNil)
+	cursor, err := query.EncodeCursor(queryData, map[string]interface{}{"name": "doe", "id": 7})
+	c.Assert(err, IsNil)
+
+	before := url.Values{"filter[order]": {"name"}, "page[before]": {cursor}}
+	queryData, err = t.builder.CreateQuery().Parse(&before)
+	c.Assert(err, IsNil)
+	canonical, err := queryData.Canonical()
+	c.Assert(err, IsNil)
+	c.Assert(canonical.GetCursor(), Equals, queryData.GetCursor())
+	c.Assert(canonical.GetFilter(), Equals, queryData.GetCursor().Predicate)
+	encoded, err := t.builder.CreateQuery().Encode(canonical)
+	c.Assert(err, IsNil)
+	c.Assert(encoded["filter[order]"], DeepEquals, []string{"name", "id"})
+	c.Assert(encoded.Get("filter[binding]"), Equals, "")
+
+	after := t.fingerprint(c, url.Values{"filter[order]": {"name"}, "page[after]": {cursor}})
+	c.Assert(t.fingerprint(c, before), Not(Equals), after)
+	c.Assert(t.fingerprint(c, values), Not(Equals), after)
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"net/url"
+
+	"github.com/cbrand/go-filterparams"
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+// Param returns a parameter which filters the field with the given name
+// by the filter and value. The name is used as alias.
+func Param(name string, filter *definition.Filter, value interface{}) *definition.Parameter {
+	return AliasedParam(name, filter, name, value)
+}
+
+// AliasedParam returns a parameter with the given alias which can be used
+// to reference the same field multiple times.
+func AliasedParam(name string, filter *definition.Filter, alias string, value interface{}) *definition.Parameter {
+	parameter := definition.NewParameter(alias)
+	parameter.Name = name
+	parameter.Filter = filter
+	parameter.Value = value
+	return parameter
+}
+
+// And combines all statements with an AND binding.
+func And(left, right interface{}, others ...interface{}) interface{} {
+	and := definition.NewAnd()
+	and.Left = left
+	and.Right = right
+	if len(others) > 0 {
+		return And(and, others[0], others[1:]...)
+	}
+	return and
+}
+
+// Or combines all statements with an OR binding.
+func Or(left, right interface{}, others ...interface{}) interface{} {
+	or := definition.NewOr()
+	or.Left = left
+	or.Right = right
+	if len(others) > 0 {
+		return Or(or, others[0], others[1:]...)
+	}
+	return or
+}
+
+// Not negates the given statement.
+func Not(statement interface{}) interface{} {
+	return definition.NewNegate(statement)
+}
+
+// Query collects the filters and orders of a request against a
+// filterparams based API.
+type Query struct {
+	filter interface{}
+	orders []*definition.Order
+}
+
+// Filter adds the statement to the query. Multiple statements are
+// combined with an AND binding.
+func (q *Query) Filter(statement interface{}) *Query {
+	if q.filter == nil {
+		q.filter = statement
+	} else {
+		q.filter = And(q.filter, statement)
+	}
+	return q
+}
+
+// OrderBy adds an ascending order by the given field.
+func (q *Query) OrderBy(name string) *Query {
+	q.orders = append(q.orders, definition.NewOrderAsc(name))
+	return q
+}
+
+// OrderByDesc adds a descending order by the given field.
+func (q *Query) OrderByDesc(name string) *Query {
+	q.orders = append(q.orders, definition.NewOrderDesc(name))
+	return q
+}
+
+// AddOrder adds the order, e.g. one which places null values last.
+func (q *Query) AddOrder(order *definition.Order) *Query {
+	q.orders = append(q.orders, order)
+	return q
+}
+
+// QueryData returns the configured filter and orders.
+func (q *Query) QueryData() *filterparams.QueryData {
+	return filterparams.NewQueryData(q.filter, q.orders)
+}
+
+// Values returns the query parameters of the query.
+func (q *Query) Values() (url.Values, error) {
+	return filterparams.EncodeQueryData(q.QueryData())
+}
+
+// Encode returns the URL encoded query string of the query.
+func (q *Query) Encode() (string, error) {
+	values, err := q.Values()
+	if err != nil {
+		return "", err
+	}
+	return values.Encode(), nil
+}
+
+// NewQuery returns an empty query.
+func NewQuery() *Query {
+	return &Query{
+		orders: []*definition.Order{},
+	}
+}
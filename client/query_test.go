@@ -0,0 +1,93 @@
+package client_test
+
+import (
+	"net/url"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/cbrand/go-filterparams"
+	"github.com/cbrand/go-filterparams/client"
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+var _ = Suite(&QueryTest{})
+
+type QueryTest struct {
+	query *filterparams.Query
+}
+
+func (t *QueryTest) SetUpTest(c *C) {
+	builder := filterparams.NewBuilder()
+	builder.EnableFilter(definition.FilterEq)
+	builder.EnableFilter(definition.FilterLike)
+	builder.EnableFilter(definition.FilterIn)
+	builder.EnableFilter(definition.FilterGte)
+	t.query = builder.CreateQuery()
+}
+
+func (t *QueryTest) parse(c *C, query *client.Query) *filterparams.QueryData {
+	encoded, err := query.Encode()
+	c.Assert(err, IsNil)
+	values, err := url.ParseQuery(encoded)
+	c.Assert(err, IsNil)
+	queryData, err := t.query.Parse(&values)
+	c.Assert(err, IsNil)
+	return queryData
+}
+
+func (t *QueryTest) TestEmpty(c *C) {
+	encoded, err := client.NewQuery().Encode()
+	c.Assert(err, IsNil)
+	c.Assert(encoded, Equals, "")
+}
+
+func (t *QueryTest) TestParam(c *C) {
+	query := client.NewQuery().Filter(client.Param("name", definition.FilterLike, "Jo%"))
+	values, err := query.Values()
+	c.Assert(err, IsNil)
+	c.Assert(values, DeepEquals, url.Values{
+		"filter[param][name][like]": {"Jo%"},
+		"filter[binding]":           {"name"},
+	})
+}
+
+func (t *QueryTest) TestTree(c *C) {
+	query := client.NewQuery().
+		Filter(client.Or(
+			client.AliasedParam("name", definition.FilterLike, "jo", "Jo%"),
+			client.AliasedParam("name", definition.FilterEq, "doe", "Doe"),
+			client.Not(client.Param("status", definition.FilterIn, []string{"deleted", "banned"})),
+		)).
+		Filter(client.Param("created", definition.FilterGte, time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC))).
+		OrderBy("name").
+		OrderByDesc("created")
+	queryData := t.parse(c, query)
+	c.Assert(queryData, DeepEquals, filterparams.NewQueryData(
+		client.And(
+			client.Or(
+				client.AliasedParam("name", definition.FilterLike, "jo", "Jo%"),
+				client.AliasedParam("name", definition.FilterEq, "doe", "Doe"),
+				client.Not(client.Param("status", definition.FilterIn, []string{"deleted", "banned"})),
+			),
+			client.Param("created", definition.FilterGte, "2015-01-02T00:00:00Z"),
+		),
+		[]*definition.Order{definition.NewOrderAsc("name"), definition.NewOrderDesc("created")},
+	))
+}
+
+func (t *QueryTest) TestAnd(c *C) {
+	a := client.Param("a", definition.FilterEq, "1")
+	b := client.Param("b", definition.FilterEq, "2")
+	d := client.Param("d", definition.FilterEq, "3")
+	and := client.And(a, b, d).(*definition.And)
+	c.Assert(and.Right, Equals, d)
+	c.Assert(and.Left.(*definition.And).Left, Equals, a)
+}
+
+func (t *QueryTest) TestAddOrder(c *C) {
+	order := definition.NewOrderDesc("balance")
+	order.SetNulls(definition.NullsLast)
+	queryData := t.parse(c, client.NewQuery().AddOrder(order))
+	c.Assert(queryData.GetOrders(), DeepEquals, []*definition.Order{order})
+}
@@ -0,0 +1,305 @@
+package filterparams
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+const (
+	pageAfterKey  = "page[after]"
+	pageBeforeKey = "page[before]"
+)
+
+// MinCursorSecretLength is the minimum length in bytes of the secret which
+// signs the cursors. It matches the output size of SHA-256.
+const MinCursorSecretLength = 32
+
+// ErrCursorsDisabled is returned if a cursor should be encoded but cursor
+// pagination has not been enabled on the query builder.
+var ErrCursorsDisabled = errors.New("Cursor pagination is not enabled")
+
+// Cursor is the position of a page[after] or page[before] cursor.
+type Cursor struct {
+	// Before is set for page[before]. The orders of the query data are
+	// reversed in this case so the entries closest to the cursor are
+	// returned first. The entries have to be reversed for display.
+	Before bool
+	// Values are the values of the order fields at the position keyed by
+	// the order names. Null values are nil.
+	Values map[string]interface{}
+	// Predicate selects the entries after the position in the order of the
+	// query data. It is combined with the filter by QueryData.GetFilter.
+	Predicate definition.Node
+}
+
+// InvalidCursorError indicates that a cursor could not be decoded, has
+// been tampered with or has been created for different orders.
+type InvalidCursorError struct {
+	errorKey
+	Cursor string
+	Reason string
+}
+
+// Error returns the formatted error message.
+func (i *InvalidCursorError) Error() string {
+	return fmt.Sprintf("The cursor \"%s\" is invalid: %s", i.Cursor, i.Reason)
+}
+
+// NewInvalidCursorError generates the error for the passed cursor.
+func NewInvalidCursorError(cursor, reason string) *InvalidCursorError {
+	return &InvalidCursorError{
+		Cursor: cursor,
+		Reason: reason,
+	}
+}
+
+// keyset configures the cursor pagination.
+type keyset struct {
+	tiebreaker string
+	secret     []byte
+}
+
+// cursorPayload is the signed content of a cursor. Null values are encoded
+// as JSON null.
+type cursorPayload struct {
+	Orders []string  `json:"o"`
+	Values []*string `json:"v"`
+}
+
+// sign encodes the payload and appends its HMAC.
+func (k *keyset) sign(payload *cursorPayload) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(k.mac(encoded)), nil
+}
+
+// verify checks the HMAC of the cursor and decodes its payload.
+func (k *keyset) verify(cursor string) (*cursorPayload, error) {
+	parts := strings.Split(cursor, ".")
+	if len(parts) != 2 {
+		return nil, NewInvalidCursorError(cursor, "malformed cursor")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, k.mac(parts[0])) {
+		return nil, NewInvalidCursorError(cursor, "invalid signature")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, NewInvalidCursorError(cursor, "malformed cursor")
+	}
+	payload := &cursorPayload{}
+	if err := json.Unmarshal(data, payload); err != nil || len(payload.Orders) != len(payload.Values) {
+		return nil, NewInvalidCursorError(cursor, "malformed cursor")
+	}
+	return payload, nil
+}
+
+// mac returns the HMAC-SHA256 of the encoded payload.
+func (k *keyset) mac(encoded string) []byte {
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// withTiebreaker appends an ascending order by the tiebreaker field unless
+// the orders already contain it. The tiebreaker makes the order unique.
+func (q *Query) withTiebreaker(orders []*definition.Order) []*definition.Order {
+	tiebreakerFound := false
+	for _, order := range orders {
+		if order.GetOrderBy() == q.keyset.tiebreaker {
+			tiebreakerFound = true
+		}
+	}
+	if tiebreakerFound {
+		return orders
+	}
+	tiebreaker := definition.NewOrderAsc(q.keyset.tiebreaker)
+	if field := q.getField(q.keyset.tiebreaker); field != nil {
+		tiebreaker.SetBackendName(field.BackendName)
+	}
+	return append(orders, tiebreaker)
+}
+
+// parseCursor decodes the page[after] or page[before] cursor and creates
+// the seek predicate for the orders. It returns nil if no cursor has been
+// passed.
+func (q *Query) parseCursor(values *url.Values, orders []*definition.Order) (*Cursor, error) {
+	key := pageAfterKey
+	if hasValue(values, pageBeforeKey) {
+		if hasValue(values, pageAfterKey) {
+			return nil, invalidPage(values, pageBeforeKey, "page[after] and page[before] can't be combined")
+		}
+		key = pageBeforeKey
+	} else if !hasValue(values, pageAfterKey) {
+		return nil, nil
+	}
+	if hasValue(values, pageNumberKey) || hasValue(values, pageOffsetKey) {
+		return nil, invalidPage(values, key, "a cursor can't be combined with page[number] or page[offset]")
+	}
+
+	token := lastValue(values, key)
+	payload, err := q.keyset.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload.Orders) != len(orders) {
+		return nil, NewInvalidCursorError(token, "the cursor has been created for different orders")
+	}
+	cursor := &Cursor{Before: key == pageBeforeKey, Values: map[string]interface{}{}}
+	converted := make([]interface{}, len(orders))
+	for index, order := range orders {
+		if payload.Orders[index] != encodeOrder(order) {
+			return nil, NewInvalidCursorError(token, "the cursor has been created for different orders")
+		}
+		fieldType := q.getFieldType(order.GetOrderBy())
+		if payload.Values[index] == nil {
+			if !q.nullable(order) {
+				return nil, NewInvalidCursorError(token, fmt.Sprintf("the value of \"%s\" can't be null", order.GetOrderBy()))
+			}
+			cursor.Values[order.GetOrderBy()] = nil
+			continue
+		}
+		value, err := convertValue(order.GetOrderBy(), *payload.Values[index], fieldType)
+		if err != nil {
+			return nil, err
+		}
+		converted[index] = value
+		cursor.Values[order.GetOrderBy()] = value
+	}
+	if cursor.Before {
+		orders = reverseOrders(orders)
+	}
+	cursor.Predicate = seekPredicate(orders, converted)
+	return cursor, nil
+}
+
+// nullable returns if cursor values of the order may be null. This requires
+// an explicit null placement, as the placement of nulls depends on the
+// backend otherwise. The tiebreaker is never null.
+func (q *Query) nullable(order *definition.Order) bool {
+	return order.GetNulls() != definition.NullsDefault && order.GetOrderBy() != q.keyset.tiebreaker
+}
+
+// seekPredicate returns the condition which selects the entries following
+// the values in the lexicographic order of the orders. For the orders a, b
+// and c this is a > 1 | (a = 1 & (b > 2 | (b = 2 & c > 3))). Nil values
+// are compared with IS NULL and IS NOT NULL following the null placement
+// of their order.
+func seekPredicate(orders []*definition.Order, values []interface{}) definition.Node {
+	var predicate definition.Node
+	for index := len(orders) - 1; index >= 0; index-- {
+		order, value := orders[index], values[index]
+		var following, equal definition.Node
+		if value == nil {
+			equal = seekParameter(order, definition.FilterIsNull, nil)
+			if order.GetNulls() == definition.NullsFirst {
+				following = seekParameter(order, definition.FilterNotNull, nil)
+			}
+		} else {
+			filter := definition.FilterGt
+			if order.OrderDesc() {
+				filter = definition.FilterLt
+			}
+			equal = seekParameter(order, definition.FilterEq, value)
+			following = seekParameter(order, filter, value)
+			if order.GetNulls() == definition.NullsLast {
+				following = seekOr(following, seekParameter(order, definition.FilterIsNull, nil))
+			}
+		}
+		if predicate != nil {
+			and := definition.NewAnd()
+			and.Left = equal
+			and.Right = predicate
+			predicate = seekOr(following, and)
+		} else {
+			predicate = following
+		}
+	}
+	return predicate
+}
+
+// seekOr combines the nodes with an or. Nil nodes are skipped.
+func seekOr(left, right definition.Node) definition.Node {
+	if left == nil {
+		return right
+	}
+	or := definition.NewOr()
+	or.Left = left
+	or.Right = right
+	return or
+}
+
+// seekParameter returns the parameter comparing the field of the order
+// with the value.
+func seekParameter(order *definition.Order, filter *definition.Filter, value interface{}) *definition.Parameter {
+	parameter := definition.NewParameter(order.GetOrderBy())
+	parameter.Name = order.GetOrderBy()
+	if order.GetBackendName() != order.GetOrderBy() {
+		parameter.BackendName = order.GetBackendName()
+	}
+	if path := definition.SplitPath(order.GetOrderBy()); len(path) > 1 {
+		parameter.Path = path
+	}
+	parameter.Relationships = order.GetRelationships()
+	parameter.Filter = filter
+	parameter.Value = value
+	return parameter
+}
+
+// reverseOrders returns the orders in the opposite direction.
+func reverseOrders(orders []*definition.Order) []*definition.Order {
+	reversed := make([]*definition.Order, len(orders))
+	for index, order := range orders {
+		reversed[index] = order.Reverse()
+	}
+	return reversed
+}
+
+// EncodeCursor returns the cursor of the position of an entry in the
+// query data. The values map the names of all orders of the query data,
+// including the tiebreaker, to the values of the entry. They are formatted
+// with the types of the fields. Only orders with an explicit null placement
+// accept nil values.
+func (q *Query) EncodeCursor(data *QueryData, values map[string]interface{}) (string, error) {
+	if q.keyset == nil {
+		return "", ErrCursorsDisabled
+	}
+	orders := data.forwardOrders()
+	payload := &cursorPayload{
+		Orders: make([]string, len(orders)),
+		Values: make([]*string, len(orders)),
+	}
+	for index, order := range orders {
+		value, ok := values[order.GetOrderBy()]
+		if !ok {
+			return "", fmt.Errorf("The cursor value of \"%s\" is missing", order.GetOrderBy())
+		}
+		payload.Orders[index] = encodeOrder(order)
+		reflected := reflect.ValueOf(value)
+		for reflected.Kind() == reflect.Ptr && !reflected.IsNil() {
+			reflected = reflected.Elem()
+		}
+		if !reflected.IsValid() || reflected.Kind() == reflect.Ptr {
+			if !q.nullable(order) {
+				return "", fmt.Errorf("The cursor value of \"%s\" can't be null", order.GetOrderBy())
+			}
+			continue
+		}
+		fieldType := q.getFieldType(order.GetOrderBy())
+		formatted := formatValue(reflected.Interface(), fieldType)
+		payload.Values[index] = &formatted
+	}
+	return q.keyset.sign(payload)
+}
@@ -0,0 +1,211 @@
+package filterparams
+
+import (
+	"net/url"
+	"strings"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+var _ = Suite(&CursorTest{})
+
+type CursorTest struct {
+	builder *QueryBuilder
+	data    *url.Values
+}
+
+func (t *CursorTest) SetUpTest(c *C) {
+	t.builder = NewBuilder()
+	t.builder.EnableFilter(definition.FilterEq)
+	t.builder.EnableCursorPagination("id", []byte("0123456789abcdef0123456789abcdef"))
+	t.data = &url.Values{}
+}
+
+func (t *CursorTest) run(c *C) *QueryData {
+	queryData, err := t.builder.CreateQuery().Parse(t.data)
+	c.Assert(err, IsNil)
+	return queryData
+}
+
+// cursor returns the cursor for the position with the values after
+// parsing the current data.
+func (t *CursorTest) cursor(c *C, values map[string]interface{}) string {
+	query := t.builder.CreateQuery()
+	queryData, err := query.Parse(t.data)
+	c.Assert(err, IsNil)
+	cursor, err := query.EncodeCursor(queryData, values)
+	c.Assert(err, IsNil)
+	return cursor
+}
+
+func (t *CursorTest) expectCursorError(c *C, key string) {
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	cursorErr, ok := err.(*InvalidCursorError)
+	c.Assert(ok, Equals, true, Commentf("Unexpected error %v", err))
+	c.Assert(cursorErr.QueryKey(), Equals, key)
+}
+
+func (t *CursorTest) TestTiebreaker(c *C) {
+	t.data.Add("filter[order]", "desc(created)")
+	c.Assert(t.run(c).GetOrders(), DeepEquals, []*definition.Order{
+		definition.NewOrderDesc("created"),
+		definition.NewOrderAsc("id"),
+	})
+	t.data.Add("filter[order]", "desc(id)")
+	c.Assert(t.run(c).GetOrders(), HasLen, 2)
+	c.Assert(t.run(c).GetCursor(), IsNil)
+}
+
+func (t *CursorTest) TestShortSecret(c *C) {
+	c.Assert(func() { t.builder.EnableCursorPagination("id", nil) }, PanicMatches, ".*at least 32 bytes")
+	c.Assert(func() { t.builder.EnableCursorPagination("id", []byte("secret")) }, PanicMatches, ".*at least 32 bytes")
+}
+
+func (t *CursorTest) TestTiebreakerBackendName(c *C) {
+	t.builder.EnableField("name", "").EnableField("id", "user_id")
+	t.data.Set("filter[order]", "name")
+	orders := t.run(c).GetOrders()
+	c.Assert(orders, HasLen, 2)
+	c.Assert(orders[1].GetBackendName(), Equals, "user_id")
+}
+
+func (t *CursorTest) TestAfter(c *C) {
+	t.data.Add("filter[order]", "desc(created)")
+	t.data.Add("filter[order]", "name")
+	t.data.Set("page[after]", t.cursor(c, map[string]interface{}{"created": "2020", "name": "doe", "id": 7}))
+	queryData := t.run(c)
+	cursor := queryData.GetCursor()
+	c.Assert(cursor.Before, Equals, false)
+	c.Assert(cursor.Values, DeepEquals, map[string]interface{}{"created": "2020", "name": "doe", "id": "7"})
+	c.Assert(definition.Describe(cursor.Predicate), Equals, strings.Join([]string{
+		`or(param("created","lt",string:"2020"),and(param("created","eq",string:"2020"),`,
+		`or(param("name","gt",string:"doe"),`,
+		`and(param("name","eq",string:"doe"),param("id","gt",string:"7")))))`,
+	}, ""))
+	c.Assert(queryData.GetFilter(), Equals, cursor.Predicate)
+}
+
+func (t *CursorTest) TestBefore(c *C) {
+	t.data.Add("filter[order]", "desc(created,nullslast)")
+	t.data.Set("page[before]", t.cursor(c, map[string]interface{}{"created": "2020", "id": "7"}))
+	queryData := t.run(c)
+	c.Assert(queryData.GetCursor().Before, Equals, true)
+	c.Assert(definition.Describe(queryData.GetCursor().Predicate), Equals,
+		`or(param("created","gt",string:"2020"),and(param("created","eq",string:"2020"),param("id","lt",string:"7")))`)
+	orders := queryData.GetOrders()
+	c.Assert(orders[0].OrderDesc(), Equals, false)
+	c.Assert(orders[0].GetNulls(), Equals, definition.NullsFirst)
+	c.Assert(orders[1].OrderDesc(), Equals, true)
+
+	query := t.builder.CreateQuery()
+	cursor, err := query.EncodeCursor(queryData, map[string]interface{}{"created": "2019", "id": "3"})
+	c.Assert(err, IsNil)
+	t.data.Del("page[before]")
+	t.data.Set("page[after]", cursor)
+	c.Assert(t.run(c).GetCursor().Values["created"], Equals, "2019")
+}
+
+func (t *CursorTest) TestNulls(c *C) {
+	t.data.Set("filter[order]", "desc(created,nullslast)")
+	cursor := t.cursor(c, map[string]interface{}{"created": nil, "id": "7"})
+	t.data.Set("page[after]", cursor)
+	queryData := t.run(c)
+	c.Assert(queryData.GetCursor().Values, DeepEquals, map[string]interface{}{"created": nil, "id": "7"})
+	c.Assert(definition.Describe(queryData.GetCursor().Predicate), Equals,
+		`and(param("created","isnull",nil),param("id","gt",string:"7"))`)
+
+	t.data.Del("page[after]")
+	t.data.Set("page[before]", cursor)
+	c.Assert(definition.Describe(t.run(c).GetCursor().Predicate), Equals,
+		`or(param("created","notnull",nil),and(param("created","isnull",nil),param("id","lt",string:"7")))`)
+
+	var created *string
+	t.data.Del("page[before]")
+	t.data.Set("filter[order]", "asc(created,nullsfirst)")
+	t.data.Set("page[after]", t.cursor(c, map[string]interface{}{"created": created, "id": "7"}))
+	c.Assert(definition.Describe(t.run(c).GetCursor().Predicate), Equals,
+		`or(param("created","notnull",nil),and(param("created","isnull",nil),param("id","gt",string:"7")))`)
+
+	t.data.Del("page[after]")
+	t.data.Set("filter[order]", "created")
+	query := t.builder.CreateQuery()
+	_, err := query.EncodeCursor(t.run(c), map[string]interface{}{"created": created, "id": "7"})
+	c.Assert(err, NotNil)
+}
+
+func (t *CursorTest) TestFilterCombined(c *C) {
+	t.data.Set("filter[param][name]", "doe")
+	t.data.Set("page[after]", t.cursor(c, map[string]interface{}{"id": 7}))
+	queryData := t.run(c)
+	and, ok := queryData.GetFilter().(*definition.And)
+	c.Assert(ok, Equals, true)
+	c.Assert(and.Left.(*definition.Parameter).Name, Equals, "name")
+	c.Assert(and.Right, Equals, queryData.GetCursor().Predicate)
+
+	encoded, err := t.builder.CreateQuery().Encode(queryData)
+	c.Assert(err, IsNil)
+	c.Assert(encoded.Get("filter[binding]"), Equals, "name")
+	c.Assert(encoded["filter[order]"], DeepEquals, []string{"id"})
+}
+
+func (t *CursorTest) TestTypedValues(c *C) {
+	t.builder.SetFieldType("id", definition.TypeInt)
+	t.data.Set("page[after]", t.cursor(c, map[string]interface{}{"id": int64(7)}))
+	c.Assert(t.run(c).GetCursor().Values["id"], Equals, int64(7))
+}
+
+func (t *CursorTest) TestTampered(c *C) {
+	cursor := t.cursor(c, map[string]interface{}{"id": 7})
+	other := t.cursor(c, map[string]interface{}{"id": 8})
+	parts, otherParts := strings.Split(cursor, "."), strings.Split(other, ".")
+	for _, tampered := range []string{
+		otherParts[0] + "." + parts[1],
+		parts[0],
+		parts[0] + ".",
+		"not a cursor",
+	} {
+		t.data.Set("page[after]", tampered)
+		t.expectCursorError(c, "page[after]")
+	}
+
+	t.builder.EnableCursorPagination("id", []byte("fedcba9876543210fedcba9876543210"))
+	t.data.Del("page[after]")
+	t.data.Set("page[before]", cursor)
+	t.expectCursorError(c, "page[before]")
+}
+
+func (t *CursorTest) TestDifferentOrders(c *C) {
+	cursor := t.cursor(c, map[string]interface{}{"id": 7})
+	t.data.Set("filter[order]", "name")
+	t.data.Set("page[after]", cursor)
+	t.expectCursorError(c, "page[after]")
+	t.data.Set("filter[order]", "desc(id)")
+	t.expectCursorError(c, "page[after]")
+}
+
+func (t *CursorTest) TestCombinedPageParameters(c *C) {
+	t.builder.EnablePagination(10, 0)
+	cursor := t.cursor(c, map[string]interface{}{"id": 7})
+	t.data.Set("page[after]", cursor)
+	t.data.Set("page[size]", "5")
+	c.Assert(t.run(c).GetPage(), DeepEquals, &Page{Limit: 5})
+
+	for _, key := range []string{"page[before]", "page[number]", "page[offset]"} {
+		values := url.Values{"page[after]": {cursor}, key: {"1"}}
+		_, err := t.builder.CreateQuery().Parse(&values)
+		c.Assert(err, FitsTypeOf, &InvalidPageError{}, Commentf(key))
+	}
+}
+
+func (t *CursorTest) TestEncodeCursorErrors(c *C) {
+	query := t.builder.CreateQuery()
+	queryData := t.run(c)
+	_, err := query.EncodeCursor(queryData, map[string]interface{}{"name": "doe"})
+	c.Assert(err, NotNil)
+	_, err = query.EncodeCursor(queryData, map[string]interface{}{"id": nil})
+	c.Assert(err, NotNil)
+	_, err = NewBuilder().CreateQuery().EncodeCursor(queryData, map[string]interface{}{"id": 7})
+	c.Assert(err, Equals, ErrCursorsDisabled)
+}
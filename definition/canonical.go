@@ -0,0 +1,106 @@
+package definition
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Canonicalize returns an equivalent tree in canonical form. Nested And and
+// Or statements are flattened, their operands sorted and rebuilt as a left
+// deep tree and double negations are removed. The passed tree is not
+// modified.
+func Canonicalize(node Node) (Node, error) {
+	return Rewrite(node, func(node Node) (Node, error) {
+		switch statement := node.(type) {
+		case *Negate:
+			if negated, ok := statement.Negated.(*Negate); ok {
+				inner, _ := AsNode(negated.Negated)
+				return inner, nil
+			}
+		case *And:
+			operands := Flatten(statement)
+			sortNodes(operands)
+			return JoinLeftDeep(operands, func(left, right Node) Node {
+				and := NewAnd()
+				and.Left, and.Right = left, right
+				return and
+			}), nil
+		case *Or:
+			operands := Flatten(statement)
+			sortNodes(operands)
+			return JoinLeftDeep(operands, func(left, right Node) Node {
+				or := NewOr()
+				or.Left, or.Right = left, right
+				return or
+			}), nil
+		}
+		return node, nil
+	})
+}
+
+// Describe returns a deterministic textual representation of the tree. The
+// aliases of parameters are not part of the representation, thus trees
+// which only differ in their aliases have the same description.
+func Describe(node Node) string {
+	switch statement := node.(type) {
+	case *And:
+		return "and(" + describeNodes(statement.Children()) + ")"
+	case *Or:
+		return "or(" + describeNodes(statement.Children()) + ")"
+	case *Negate:
+		return "not(" + describeNodes(statement.Children()) + ")"
+	case *Parameter:
+		filter := ""
+		if statement.Filter != nil {
+			filter = statement.Filter.Identification
+		}
+		return fmt.Sprintf("param(%s,%s,%s)", strconv.Quote(statement.Name), strconv.Quote(filter), describeValue(statement.Value))
+	}
+	return "nil"
+}
+
+// describeNodes returns the descriptions of the nodes separated by commas.
+func describeNodes(nodes []Node) string {
+	descriptions := make([]string, len(nodes))
+	for index, node := range nodes {
+		descriptions[index] = Describe(node)
+	}
+	return strings.Join(descriptions, ",")
+}
+
+// describeValue returns a representation of the value which includes
+// its type.
+func describeValue(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return "nil"
+	case *Range:
+		return "range(" + describeValue(typed.From) + "," + describeValue(typed.To) + ")"
+	case time.Time:
+		return "time:" + typed.UTC().Format(time.RFC3339Nano)
+	}
+	reflected := reflect.ValueOf(value)
+	if reflected.Kind() == reflect.Slice || reflected.Kind() == reflect.Array {
+		items := make([]string, reflected.Len())
+		for index := range items {
+			items[index] = describeValue(reflected.Index(index).Interface())
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	}
+	return fmt.Sprintf("%T:%s", value, strconv.Quote(fmt.Sprint(value)))
+}
+
+// sortNodes sorts the nodes by their description.
+func sortNodes(nodes []Node) {
+	descriptions := make(map[Node]string, len(nodes))
+	for _, node := range nodes {
+		descriptions[node] = Describe(node)
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return descriptions[nodes[i]] < descriptions[nodes[j]]
+	})
+}
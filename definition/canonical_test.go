@@ -0,0 +1,84 @@
+package definition
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+var _ = Suite(&CanonicalTest{})
+
+type CanonicalTest struct{}
+
+func param(name string, value interface{}) *Parameter {
+	parameter := NewParameter(name)
+	parameter.Name = name
+	parameter.Filter = FilterEq
+	parameter.Value = value
+	return parameter
+}
+
+func and(left, right interface{}) *And {
+	node := NewAnd()
+	node.Left, node.Right = left, right
+	return node
+}
+
+func or(left, right interface{}) *Or {
+	node := NewOr()
+	node.Left, node.Right = left, right
+	return node
+}
+
+func (t *CanonicalTest) canonical(c *C, node Node) string {
+	canonical, err := Canonicalize(node)
+	c.Assert(err, IsNil)
+	return Describe(canonical)
+}
+
+func (t *CanonicalTest) TestFlattenAndSort(c *C) {
+	left := and(param("c", "3"), and(param("a", "1"), param("b", "2")))
+	right := and(and(param("b", "2"), param("c", "3")), param("a", "1"))
+	c.Assert(t.canonical(c, left), Equals, t.canonical(c, right))
+	c.Assert(
+		t.canonical(c, left),
+		Equals,
+		`and(and(param("a","eq",string:"1"),param("b","eq",string:"2")),param("c","eq",string:"3"))`,
+	)
+}
+
+func (t *CanonicalTest) TestMixedOperators(c *C) {
+	left := or(and(param("b", "2"), param("a", "1")), param("c", "3"))
+	right := or(param("c", "3"), and(param("a", "1"), param("b", "2")))
+	c.Assert(t.canonical(c, left), Equals, t.canonical(c, right))
+	c.Assert(t.canonical(c, left), Not(Equals), t.canonical(c, and(or(param("a", "1"), param("b", "2")), param("c", "3"))))
+}
+
+func (t *CanonicalTest) TestDoubleNegation(c *C) {
+	c.Assert(t.canonical(c, NewNegate(NewNegate(param("a", "1")))), Equals, t.canonical(c, param("a", "1")))
+	c.Assert(t.canonical(c, NewNegate(NewNegate(NewNegate(param("a", "1"))))), Equals, t.canonical(c, NewNegate(param("a", "1"))))
+}
+
+func (t *CanonicalTest) TestAliasesAreIgnored(c *C) {
+	aliased := param("a", "1")
+	aliased.Identification = "alias"
+	c.Assert(Describe(aliased), Equals, Describe(param("a", "1")))
+}
+
+func (t *CanonicalTest) TestDescribeValueTypes(c *C) {
+	c.Assert(Describe(param("a", int64(1))), Not(Equals), Describe(param("a", "1")))
+	c.Assert(Describe(param("a", []string{"1", "2"})), Equals, `param("a","eq",[string:"1",string:"2"])`)
+	c.Assert(
+		Describe(param("a", time.Date(2015, 1, 1, 2, 0, 0, 0, time.FixedZone("", 7200)))),
+		Equals,
+		Describe(param("a", time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC))),
+	)
+	c.Assert(Describe(param("a", &Range{From: 1, To: 2})), Equals, `param("a","eq",range(int:"1",int:"2"))`)
+}
+
+func (t *CanonicalTest) TestOriginalUnchanged(c *C) {
+	node := and(param("b", "2"), param("a", "1"))
+	_, err := Canonicalize(node)
+	c.Assert(err, IsNil)
+	c.Assert(node.Left.(*Parameter).Name, Equals, "b")
+}
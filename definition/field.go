@@ -0,0 +1,59 @@
+package definition
+
+// Field is a field of the resource which is allowed to be filtered
+// and ordered by.
+type Field struct {
+	// Name is the name of the field in the query parameters.
+	Name string
+	// BackendName is the name of the field in the backend, e.g. the
+	// column of a database table.
+	BackendName string
+	// Filters are the filters which are allowed for this field. If none
+	// are set all filters of the query are allowed.
+	Filters []*Filter
+	// Type converts the values of the field. If it is nil the raw
+	// string is kept.
+	Type FieldType
+}
+
+// SetType sets the type the values of the field are converted to.
+func (f *Field) SetType(fieldType FieldType) *Field {
+	f.Type = fieldType
+	return f
+}
+
+// EnableFilter allows the filter to be applied to the field.
+func (f *Field) EnableFilter(filter *Filter) *Field {
+	if f.GetFilter(filter.Identification) == nil {
+		f.Filters = append(f.Filters, filter)
+	}
+	return f
+}
+
+// HasFilters returns if the field restricts the allowed filters.
+func (f *Field) HasFilters() bool {
+	return len(f.Filters) > 0
+}
+
+// GetFilter returns the allowed filter with the given identification or
+// nil if it isn't allowed for the field.
+func (f *Field) GetFilter(identification string) *Filter {
+	for _, filter := range f.Filters {
+		if filter.Identification == identification {
+			return filter
+		}
+	}
+	return nil
+}
+
+// NewField returns a new field with the given public and backend name. If
+// the backendName is empty the public name is used for the backend.
+func NewField(name, backendName string) *Field {
+	if len(backendName) == 0 {
+		backendName = name
+	}
+	return &Field{
+		Name:        name,
+		BackendName: backendName,
+	}
+}
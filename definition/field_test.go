@@ -0,0 +1,24 @@
+package definition
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+var _ = Suite(&FieldTest{})
+
+type FieldTest struct{}
+
+func (t *FieldTest) TestNewFieldBackendName(c *C) {
+	c.Assert(NewField("name", "").BackendName, Equals, "name")
+	c.Assert(NewField("name", "user_name").BackendName, Equals, "user_name")
+}
+
+func (t *FieldTest) TestEnableFilter(c *C) {
+	field := NewField("created_at", "")
+	c.Assert(field.HasFilters(), Equals, false)
+	field.EnableFilter(FilterLt).EnableFilter(FilterGt).EnableFilter(FilterLt)
+	c.Assert(field.HasFilters(), Equals, true)
+	c.Assert(len(field.Filters), Equals, 2)
+	c.Assert(field.GetFilter("lt"), Equals, FilterLt)
+	c.Assert(field.GetFilter("eq"), IsNil)
+}
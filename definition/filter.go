@@ -1,9 +1,35 @@
 package definition
 
+// Arity describes how many values a filter expects.
+type Arity int
+
+const (
+	// ArityScalar filters expect a single value.
+	ArityScalar Arity = iota
+	// ArityList filters expect a list of values.
+	ArityList
+	// ArityRange filters expect exactly two values which are provided
+	// as a Range.
+	ArityRange
+	// ArityNone filters expect no value. The passed value is ignored.
+	ArityNone
+)
+
 // Filter is one allowed filter for the given entry.
 type Filter struct {
 	// Identification is the representation in the query parameter.
 	Identification string
+	// Arity is the amount of values the filter expects.
+	Arity Arity
+	// Untyped filters receive the raw string value, e.g. a pattern,
+	// instead of a value converted to the type of the field.
+	Untyped bool
+}
+
+// Range is the value of filters with the ArityRange arity.
+type Range struct {
+	From interface{}
+	To   interface{}
 }
 
 var (
@@ -30,13 +56,26 @@ var (
 	// FilterIn is a filter for the in comparison.
 	FilterIn = &Filter{
 		Identification: "in",
+		Arity:          ArityList,
 	}
 	// FilterLike is a filter for the SQL-LIKE clause.
 	FilterLike = &Filter{
 		Identification: "like",
+		Untyped:        true,
 	}
 	// FilterILike is a filter for the SQL-LIKE with ignoring cases.
 	FilterILike = &Filter{
 		Identification: "ilike",
+		Untyped:        true,
+	}
+	// FilterIsNull is a filter for the SQL-IS NULL clause.
+	FilterIsNull = &Filter{
+		Identification: "isnull",
+		Arity:          ArityNone,
+	}
+	// FilterNotNull is a filter for the SQL-IS NOT NULL clause.
+	FilterNotNull = &Filter{
+		Identification: "notnull",
+		Arity:          ArityNone,
 	}
 )
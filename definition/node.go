@@ -0,0 +1,179 @@
+package definition
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Node is implemented by all statements of a filter tree: And, Or,
+// Negate and Parameter.
+type Node interface {
+	ParameterHaver
+	// Children returns the direct child nodes of the node.
+	Children() []Node
+}
+
+// AsNode returns the given statement as a Node. Returns false if the
+// statement isn't a node of the filter tree.
+func AsNode(statement interface{}) (Node, bool) {
+	switch node := statement.(type) {
+	case *And:
+		return node, node != nil
+	case *Or:
+		return node, node != nil
+	case *Negate:
+		return node, node != nil
+	case *Parameter:
+		return node, node != nil
+	}
+	return nil, false
+}
+
+// Children returns the left and right node.
+func (p *LeftRight) Children() []Node {
+	return childNodes(p.Left, p.Right)
+}
+
+// Children returns the negated node.
+func (n *Negate) Children() []Node {
+	return childNodes(n.Negated)
+}
+
+// Children returns no nodes as a parameter is always a leaf.
+func (p *Parameter) Children() []Node {
+	return []Node{}
+}
+
+// childNodes returns all statements which are nodes.
+func childNodes(statements ...interface{}) []Node {
+	nodes := []Node{}
+	for _, statement := range statements {
+		if node, ok := AsNode(statement); ok {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// Visitor is called for every node by Walk. If the returned visitor is
+// not nil, the children of the node are walked with it.
+type Visitor interface {
+	Visit(node Node) Visitor
+}
+
+// Walk traverses the tree in depth-first order. It calls v.Visit(node)
+// and walks the children of the node with the returned visitor. After
+// all children have been walked, v.Visit(nil) is called on the returned
+// visitor.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+	for _, child := range node.Children() {
+		Walk(v, child)
+	}
+	v.Visit(nil)
+}
+
+// inspector adapts a function to the Visitor interface.
+type inspector func(Node) bool
+
+// Visit calls the function and stops descending if it returns false.
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses the tree in depth-first order and calls f for every
+// node. The children of a node are only inspected if f returns true.
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// RewriteFunc returns the replacement of the given node. Returning the
+// node itself keeps it unchanged.
+type RewriteFunc func(node Node) (Node, error)
+
+// Rewrite transforms the tree bottom-up. The children of a node are
+// rewritten before the node itself is passed to fn. The passed tree is
+// not modified, And, Or and Negate nodes are copied.
+func Rewrite(node Node, fn RewriteFunc) (Node, error) {
+	var rewritten Node
+	switch statement := node.(type) {
+	case *And:
+		left, right, err := rewriteLeftRight(&statement.LeftRight, fn)
+		if err != nil {
+			return nil, err
+		}
+		and := NewAnd()
+		and.Left, and.Right = left, right
+		rewritten = and
+	case *Or:
+		left, right, err := rewriteLeftRight(&statement.LeftRight, fn)
+		if err != nil {
+			return nil, err
+		}
+		or := NewOr()
+		or.Left, or.Right = left, right
+		rewritten = or
+	case *Negate:
+		negated, err := rewriteStatement(statement.Negated, fn)
+		if err != nil {
+			return nil, err
+		}
+		rewritten = NewNegate(negated)
+	case *Parameter:
+		rewritten = statement
+	default:
+		return nil, fmt.Errorf("Unexpected node %T in filter", node)
+	}
+	return fn(rewritten)
+}
+
+// rewriteLeftRight rewrites both sides of the node.
+func rewriteLeftRight(node *LeftRight, fn RewriteFunc) (Node, Node, error) {
+	left, err := rewriteStatement(node.Left, fn)
+	if err != nil {
+		return nil, nil, err
+	}
+	right, err := rewriteStatement(node.Right, fn)
+	if err != nil {
+		return nil, nil, err
+	}
+	return left, right, nil
+}
+
+// rewriteStatement rewrites the statement if it is a node.
+func rewriteStatement(statement interface{}, fn RewriteFunc) (Node, error) {
+	node, ok := AsNode(statement)
+	if !ok {
+		return nil, fmt.Errorf("Unexpected node %T in filter", statement)
+	}
+	return Rewrite(node, fn)
+}
+
+// Flatten returns the operands of nested statements of the same type as
+// the node, e.g. a, b and c for the And statement of (a&b)&c.
+func Flatten(node Node) []Node {
+	operands := []Node{}
+	for _, child := range node.Children() {
+		if reflect.TypeOf(child) == reflect.TypeOf(node) {
+			operands = append(operands, Flatten(child)...)
+		} else {
+			operands = append(operands, child)
+		}
+	}
+	return operands
+}
+
+// JoinLeftDeep combines the operands into a left deep tree with the
+// statements returned by combine. At least one operand must be passed.
+func JoinLeftDeep(operands []Node, combine func(left, right Node) Node) Node {
+	result := operands[0]
+	for _, operand := range operands[1:] {
+		result = combine(result, operand)
+	}
+	return result
+}
@@ -0,0 +1,138 @@
+package definition
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+var _ = Suite(&NodeTest{})
+
+type NodeTest struct {
+	tree Node
+}
+
+func (t *NodeTest) SetUpTest(c *C) {
+	or := NewOr()
+	or.Left = NewParameter("a")
+	or.Right = NewNegate(NewParameter("b"))
+	and := NewAnd()
+	and.Left = or
+	and.Right = NewParameter("c")
+	t.tree = and
+}
+
+// identifications returns the identifications of all parameters in the
+// order they are inspected.
+func identifications(node Node) []string {
+	names := []string{}
+	Inspect(node, func(node Node) bool {
+		if parameter, ok := node.(*Parameter); ok {
+			names = append(names, parameter.Identification)
+		}
+		return true
+	})
+	return names
+}
+
+func (t *NodeTest) TestAsNode(c *C) {
+	_, ok := AsNode(NewParameter("a"))
+	c.Assert(ok, Equals, true)
+	_, ok = AsNode("a")
+	c.Assert(ok, Equals, false)
+	var parameter *Parameter
+	_, ok = AsNode(parameter)
+	c.Assert(ok, Equals, false)
+}
+
+func (t *NodeTest) TestInspect(c *C) {
+	c.Assert(identifications(t.tree), DeepEquals, []string{"a", "b", "c"})
+}
+
+func (t *NodeTest) TestInspectSkipChildren(c *C) {
+	visited := 0
+	Inspect(t.tree, func(node Node) bool {
+		if node != nil {
+			visited++
+		}
+		_, isOr := node.(*Or)
+		return !isOr
+	})
+	c.Assert(visited, Equals, 3)
+}
+
+type countingVisitor struct {
+	entered int
+	left    int
+}
+
+func (v *countingVisitor) Visit(node Node) Visitor {
+	if node == nil {
+		v.left++
+	} else {
+		v.entered++
+	}
+	return v
+}
+
+func (t *NodeTest) TestWalk(c *C) {
+	visitor := &countingVisitor{}
+	Walk(visitor, t.tree)
+	c.Assert(visitor.entered, Equals, 6)
+	c.Assert(visitor.left, Equals, 6)
+}
+
+func (t *NodeTest) TestRewrite(c *C) {
+	rewritten, err := Rewrite(t.tree, func(node Node) (Node, error) {
+		if negate, ok := node.(*Negate); ok {
+			return negate.Children()[0], nil
+		}
+		if parameter, ok := node.(*Parameter); ok {
+			return NewParameter(parameter.Identification + "2"), nil
+		}
+		return node, nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(identifications(rewritten), DeepEquals, []string{"a2", "b2", "c2"})
+	_, ok := rewritten.(*And).Left.(*Or).Right.(*Parameter)
+	c.Assert(ok, Equals, true)
+	c.Assert(identifications(t.tree), DeepEquals, []string{"a", "b", "c"})
+}
+
+func (t *NodeTest) TestRewriteUnexpectedNode(c *C) {
+	and := NewAnd()
+	and.Left = NewParameter("a")
+	and.Right = "b"
+	_, err := Rewrite(and, func(node Node) (Node, error) {
+		return node, nil
+	})
+	c.Assert(err, NotNil)
+}
+
+func (t *NodeTest) TestGetParametersSkipsUnexpected(c *C) {
+	and := NewAnd()
+	and.Left = NewParameter("a")
+	and.Right = "b"
+	c.Assert(len(and.GetParameters()), Equals, 1)
+}
+
+func (t *NodeTest) TestFlattenAndJoinLeftDeep(c *C) {
+	inner := NewAnd()
+	inner.Left = NewParameter("a")
+	inner.Right = NewParameter("b")
+	outer := NewAnd()
+	outer.Left = inner
+	outer.Right = NewOr()
+	outer.Right.(*Or).Left = NewParameter("c")
+	outer.Right.(*Or).Right = NewParameter("d")
+	operands := Flatten(outer)
+	c.Assert(operands, HasLen, 3)
+	c.Assert(Describe(operands[2]), Equals, Describe(outer.Right.(*Or)))
+
+	joined := JoinLeftDeep(operands, func(left, right Node) Node {
+		or := NewOr()
+		or.Left = left
+		or.Right = right
+		return or
+	})
+	c.Assert(identifications(joined), DeepEquals, []string{"a", "b", "c", "d"})
+	c.Assert(Flatten(joined), HasLen, 4)
+}
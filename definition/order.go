@@ -2,11 +2,26 @@ package definition
 
 import "strings"
 
+// Nulls defines where null values are placed by an order.
+type Nulls int
+
+const (
+	// NullsDefault keeps the placement of the backend.
+	NullsDefault Nulls = iota
+	// NullsFirst places null values before all other values.
+	NullsFirst
+	// NullsLast places null values after all other values.
+	NullsLast
+)
+
 // Order is the representation on how one order item should be
 // applied to a collection resource.
 type Order struct {
 	orderBy string
 	orderDesc bool
+	backendName string
+	nulls Nulls
+	relationships []*Relationship
 }
 
 // GetOrderBy returns the parameter name it should be ordered by.
@@ -14,11 +29,64 @@ func (o *Order) GetOrderBy() string {
 	return o.orderBy
 }
 
+// GetBackendName returns the name of the field in the backend which should be
+// ordered by. If no mapping has been configured the order name is returned.
+func (o *Order) GetBackendName() string {
+	if len(o.backendName) > 0 {
+		return o.backendName
+	}
+	return o.orderBy
+}
+
+// SetBackendName sets the name of the field in the backend.
+func (o *Order) SetBackendName(backendName string) {
+	o.backendName = backendName
+}
+
+// GetRelationships returns the relationships traversed by a dotted order
+// name like "author.name". It is nil for fields of the resource itself.
+func (o *Order) GetRelationships() []*Relationship {
+	return o.relationships
+}
+
+// SetRelationships sets the relationships traversed by the order name.
+func (o *Order) SetRelationships(relationships []*Relationship) {
+	o.relationships = relationships
+}
+
 // OrderDesc returns if the sorting should be ordered by in descending order.
 func (o *Order) OrderDesc() bool {
 	return o.orderDesc
 }
 
+// GetNulls returns where null values should be placed.
+func (o *Order) GetNulls() Nulls {
+	return o.nulls
+}
+
+// SetNulls sets where null values should be placed.
+func (o *Order) SetNulls(nulls Nulls) {
+	o.nulls = nulls
+}
+
+// Reverse returns an order by the same field in the opposite direction.
+// Null values placed first by the order are placed last and vice versa.
+func (o *Order) Reverse() *Order {
+	reversed := &Order{
+		orderBy:     o.orderBy,
+		orderDesc:   !o.orderDesc,
+		backendName: o.backendName,
+		relationships: o.relationships,
+	}
+	switch o.nulls {
+	case NullsFirst:
+		reversed.nulls = NullsLast
+	case NullsLast:
+		reversed.nulls = NullsFirst
+	}
+	return reversed
+}
+
 func newOrder(orderBy string) *Order {
 	return &Order{
 		orderBy: orderBy,
@@ -19,11 +19,14 @@ type LeftRight struct {
 }
 
 // GetParameters provides all Parameters which are nested in the parameter entry.
+// Entries which do not provide parameters are skipped.
 func (p *LeftRight) GetParameters() []*Parameter {
 	parameters := []*Parameter{}
-	left, right := p.Left.(ParameterHaver), p.Right.(ParameterHaver)
-	parameters = append(parameters, left.GetParameters()...)
-	parameters = append(parameters, right.GetParameters()...)
+	for _, entry := range []interface{}{p.Left, p.Right} {
+		if haver, ok := entry.(ParameterHaver); ok {
+			parameters = append(parameters, haver.GetParameters()...)
+		}
+	}
 	return parameters
 }
 
@@ -55,10 +58,21 @@ type Parameter struct {
 	Identification string
 	// Name is the name of the field.
 	Name           string
+	// BackendName is the name of the field in the backend if a field
+	// mapping has been configured.
+	BackendName    string
 	// Filter is the filter entry for the given entry.
 	Filter         *Filter
 	// Value is the value which the entry should be filtered by.
 	Value          interface{}
+	// Path are the segments of a dotted name, e.g. "author" and "name" for
+	// the name "author.name". It is empty for plain names, use GetPath to
+	// access the segments of any name.
+	Path           []string
+	// Relationships are the relationships traversed by the path if they
+	// have been declared. The BackendName is the name of the field of the
+	// last relationship in this case.
+	Relationships  []*Relationship
 }
 
 // GetParameters returns the parameter itself and thus implements the
@@ -67,6 +81,24 @@ func (p *Parameter) GetParameters() []*Parameter {
 	return []*Parameter{p}
 }
 
+// GetBackendName returns the name of the field in the backend. If no
+// mapping has been configured the name of the parameter is returned.
+func (p *Parameter) GetBackendName() string {
+	if len(p.BackendName) > 0 {
+		return p.BackendName
+	}
+	return p.Name
+}
+
+// GetPath returns the segments of the name. If no path has been set it is
+// derived from the name.
+func (p *Parameter) GetPath() []string {
+	if len(p.Path) > 0 {
+		return p.Path
+	}
+	return SplitPath(p.Name)
+}
+
 // NewParameter returns a new parameter initialized with the given
 // identification.
 func NewParameter(identification string) *Parameter {
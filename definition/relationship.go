@@ -0,0 +1,181 @@
+package definition
+
+import (
+	"strings"
+)
+
+// PathSeparator separates the segments of a relationship path, e.g.
+// "author.name".
+const PathSeparator = "."
+
+// Quantifier defines how a filter on a to-many relationship is applied to
+// the related items.
+type Quantifier int
+
+const (
+	// QuantifierAny matches if at least one related item matches.
+	QuantifierAny Quantifier = iota
+	// QuantifierAll matches if every related item matches. Resources
+	// without related items match as well.
+	QuantifierAll
+)
+
+// Relationship is a related resource whose fields can be filtered with a
+// dotted path like "author.name".
+type Relationship struct {
+	// Name is the name of the relationship in the query parameters.
+	Name string
+	// ResourceType is the JSON:API type of the related resource. Use
+	// GetResourceType to fall back to the name.
+	ResourceType string
+	// Table is the table of the related resource in the backend.
+	Table string
+	// LocalKey is the column of the owning resource which references the
+	// related resource.
+	LocalKey string
+	// ForeignKey is the column of the related resource which is referenced
+	// by the LocalKey.
+	ForeignKey string
+	// Quantifier is used if multiple resources are related. Defaults to
+	// QuantifierAny.
+	Quantifier Quantifier
+	// ToOne marks relationships with at most one related resource, e.g.
+	// "author". Relationships are to-many by default.
+	ToOne bool
+	// Fields are the fields of the related resource which may be filtered.
+	Fields []*Field
+	// Relationships are the relationships of the related resource.
+	Relationships []*Relationship
+}
+
+// SetQuantifier sets how filters are applied to multiple related items.
+func (r *Relationship) SetQuantifier(quantifier Quantifier) *Relationship {
+	r.Quantifier = quantifier
+	return r
+}
+
+// SetToOne marks the relationship as relating at most one resource.
+func (r *Relationship) SetToOne(toOne bool) *Relationship {
+	r.ToOne = toOne
+	return r
+}
+
+// SetResourceType sets the JSON:API type of the related resource, e.g.
+// "people" for the relationship "author".
+func (r *Relationship) SetResourceType(resourceType string) *Relationship {
+	r.ResourceType = resourceType
+	return r
+}
+
+// GetResourceType returns the JSON:API type of the related resource. If
+// none has been set the name of the relationship is returned.
+func (r *Relationship) GetResourceType() string {
+	if len(r.ResourceType) > 0 {
+		return r.ResourceType
+	}
+	return r.Name
+}
+
+// EnableField allows the field of the related resource to be filtered.
+func (r *Relationship) EnableField(name, backendName string) *Relationship {
+	return r.AddField(NewField(name, backendName))
+}
+
+// AddField allows the field to be filtered. A previously added field with
+// the same name is replaced.
+func (r *Relationship) AddField(field *Field) *Relationship {
+	for index, existing := range r.Fields {
+		if existing.Name == field.Name {
+			r.Fields[index] = field
+			return r
+		}
+	}
+	r.Fields = append(r.Fields, field)
+	return r
+}
+
+// GetField returns the field with the given name or nil if it hasn't
+// been added.
+func (r *Relationship) GetField(name string) *Field {
+	for _, field := range r.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// AddRelationship adds a nested relationship of the related resource. A
+// previously added relationship with the same name is replaced.
+func (r *Relationship) AddRelationship(relationship *Relationship) *Relationship {
+	r.Relationships = AddRelationship(r.Relationships, relationship)
+	return r
+}
+
+// GetRelationship returns the nested relationship with the given name or
+// nil if it hasn't been added.
+func (r *Relationship) GetRelationship(name string) *Relationship {
+	return GetRelationship(r.Relationships, name)
+}
+
+// AddRelationship adds the relationship to the list. An entry with the same
+// name is replaced.
+func AddRelationship(relationships []*Relationship, relationship *Relationship) []*Relationship {
+	for index, existing := range relationships {
+		if existing.Name == relationship.Name {
+			relationships[index] = relationship
+			return relationships
+		}
+	}
+	return append(relationships, relationship)
+}
+
+// GetRelationship returns the relationship with the given name from the
+// list or nil if it doesn't exist.
+func GetRelationship(relationships []*Relationship, name string) *Relationship {
+	for _, relationship := range relationships {
+		if relationship.Name == name {
+			return relationship
+		}
+	}
+	return nil
+}
+
+// ResolvePath resolves a dotted path against the fields and relationships
+// of a resource. The relationships traversed by the path and the field
+// it ends in are returned. The field is nil if the path can't be resolved.
+func ResolvePath(path []string, fields []*Field, relationships []*Relationship) (*Field, []*Relationship) {
+	traversed := []*Relationship{}
+	for _, segment := range path[:len(path)-1] {
+		relationship := GetRelationship(relationships, segment)
+		if relationship == nil {
+			return nil, nil
+		}
+		traversed = append(traversed, relationship)
+		fields, relationships = relationship.Fields, relationship.Relationships
+	}
+	name := path[len(path)-1]
+	for _, field := range fields {
+		if field.Name == name {
+			return field, traversed
+		}
+	}
+	return nil, nil
+}
+
+// SplitPath splits a dotted name into its segments.
+func SplitPath(name string) []string {
+	return strings.Split(name, PathSeparator)
+}
+
+// NewRelationship returns a relationship with the given name. The related
+// resource is stored in table and joined by comparing its foreignKey with
+// the localKey of the owning resource.
+func NewRelationship(name, table, localKey, foreignKey string) *Relationship {
+	return &Relationship{
+		Name:       name,
+		Table:      table,
+		LocalKey:   localKey,
+		ForeignKey: foreignKey,
+	}
+}
@@ -0,0 +1,47 @@
+package definition
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+var _ = Suite(&RelationshipTest{})
+
+type RelationshipTest struct{}
+
+func (t *RelationshipTest) TestResolvePath(c *C) {
+	author := NewRelationship("author", "users", "author_id", "id").EnableField("name", "full_name")
+	posts := NewRelationship("posts", "posts", "id", "blog_id").AddRelationship(author)
+	fields := []*Field{NewField("title", "")}
+	relationships := []*Relationship{posts}
+
+	field, traversed := ResolvePath(SplitPath("posts.author.name"), fields, relationships)
+	c.Assert(field.BackendName, Equals, "full_name")
+	c.Assert(traversed, DeepEquals, []*Relationship{posts, author})
+
+	field, traversed = ResolvePath(SplitPath("title"), fields, relationships)
+	c.Assert(field.Name, Equals, "title")
+	c.Assert(traversed, HasLen, 0)
+
+	for _, name := range []string{"posts.title", "posts.author", "comments.name", "name"} {
+		field, _ = ResolvePath(SplitPath(name), fields, relationships)
+		c.Assert(field, IsNil, Commentf("%s", name))
+	}
+}
+
+func (t *RelationshipTest) TestReplace(c *C) {
+	relationship := NewRelationship("author", "users", "author_id", "id").EnableField("name", "").EnableField("name", "full_name")
+	c.Assert(relationship.Fields, HasLen, 1)
+	c.Assert(relationship.GetField("name").BackendName, Equals, "full_name")
+
+	relationship.AddRelationship(NewRelationship("company", "companies", "company_id", "id"))
+	relationship.AddRelationship(NewRelationship("company", "firms", "company_id", "id"))
+	c.Assert(relationship.Relationships, HasLen, 1)
+	c.Assert(relationship.GetRelationship("company").Table, Equals, "firms")
+	c.Assert(relationship.GetRelationship("unknown"), IsNil)
+}
+
+func (t *RelationshipTest) TestResourceType(c *C) {
+	relationship := NewRelationship("author", "users", "author_id", "id")
+	c.Assert(relationship.GetResourceType(), Equals, "author")
+	c.Assert(relationship.SetResourceType("people").GetResourceType(), Equals, "people")
+}
@@ -0,0 +1,202 @@
+package definition
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType converts the raw value of a query parameter into the type
+// of the field and back.
+type FieldType interface {
+	// Name returns the name of the type which is used in error messages.
+	Name() string
+	// Convert converts the raw value into the typed representation.
+	Convert(value string) (interface{}, error)
+	// Format returns the raw representation of a converted value.
+	Format(value interface{}) string
+}
+
+// UUID is the canonical lower case representation of a UUID.
+type UUID string
+
+// Decimal is the textual representation of a validated decimal number. It
+// is kept as a string to avoid losing precision.
+type Decimal string
+
+var uuidMatcher = regexp.MustCompile("^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$")
+var decimalMatcher = regexp.MustCompile("^[+-]?([0-9]+(\\.[0-9]*)?|\\.[0-9]+)$")
+
+// intType converts values to int64.
+type intType struct{}
+
+// Name returns "int".
+func (t *intType) Name() string {
+	return "int"
+}
+
+// Convert parses the value as a base 10 int64.
+func (t *intType) Convert(value string) (interface{}, error) {
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// Format returns the decimal representation of the value.
+func (t *intType) Format(value interface{}) string {
+	return fmt.Sprint(value)
+}
+
+// floatType converts values to float64.
+type floatType struct{}
+
+// Name returns "float".
+func (t *floatType) Name() string {
+	return "float"
+}
+
+// Convert parses the value as a float64.
+func (t *floatType) Convert(value string) (interface{}, error) {
+	return strconv.ParseFloat(value, 64)
+}
+
+// Format returns the shortest representation of the value.
+func (t *floatType) Format(value interface{}) string {
+	if converted, ok := value.(float64); ok {
+		return strconv.FormatFloat(converted, 'g', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// boolType converts values to bool.
+type boolType struct{}
+
+// Name returns "bool".
+func (t *boolType) Name() string {
+	return "bool"
+}
+
+// Convert parses the value with strconv.ParseBool.
+func (t *boolType) Convert(value string) (interface{}, error) {
+	return strconv.ParseBool(value)
+}
+
+// Format returns "true" or "false".
+func (t *boolType) Format(value interface{}) string {
+	return fmt.Sprint(value)
+}
+
+// uuidType validates and normalizes UUIDs.
+type uuidType struct{}
+
+// Name returns "uuid".
+func (t *uuidType) Name() string {
+	return "uuid"
+}
+
+// Convert validates the UUID and returns it in lower case.
+func (t *uuidType) Convert(value string) (interface{}, error) {
+	normalized := strings.ToLower(value)
+	if !uuidMatcher.MatchString(normalized) {
+		return nil, fmt.Errorf("\"%s\" is not a valid UUID", value)
+	}
+	return UUID(normalized), nil
+}
+
+// Format returns the UUID.
+func (t *uuidType) Format(value interface{}) string {
+	return fmt.Sprint(value)
+}
+
+// decimalType validates decimal numbers.
+type decimalType struct{}
+
+// Name returns "decimal".
+func (t *decimalType) Name() string {
+	return "decimal"
+}
+
+// Convert validates the decimal number and returns it as a Decimal.
+func (t *decimalType) Convert(value string) (interface{}, error) {
+	if !decimalMatcher.MatchString(value) {
+		return nil, fmt.Errorf("\"%s\" is not a valid decimal", value)
+	}
+	return Decimal(value), nil
+}
+
+// Format returns the decimal number.
+func (t *decimalType) Format(value interface{}) string {
+	return fmt.Sprint(value)
+}
+
+// timeType parses values with the configured layout.
+type timeType struct {
+	layout string
+}
+
+// Name returns "time" followed by the layout in parentheses.
+func (t *timeType) Name() string {
+	return "time(" + t.layout + ")"
+}
+
+// Convert parses the value with the layout of the type.
+func (t *timeType) Convert(value string) (interface{}, error) {
+	return time.Parse(t.layout, value)
+}
+
+// Format returns the time in the layout of the type.
+func (t *timeType) Format(value interface{}) string {
+	if converted, ok := value.(time.Time); ok {
+		return converted.Format(t.layout)
+	}
+	return fmt.Sprint(value)
+}
+
+// enumType only allows a fixed set of strings.
+type enumType struct {
+	values []string
+}
+
+// Name returns "enum" followed by the allowed values in parentheses.
+func (t *enumType) Name() string {
+	return "enum(" + strings.Join(t.values, ",") + ")"
+}
+
+// Convert returns the value if it is one of the allowed values.
+func (t *enumType) Convert(value string) (interface{}, error) {
+	for _, allowed := range t.values {
+		if allowed == value {
+			return value, nil
+		}
+	}
+	return nil, fmt.Errorf("\"%s\" is not one of %s", value, strings.Join(t.values, ", "))
+}
+
+// Format returns the value.
+func (t *enumType) Format(value interface{}) string {
+	return fmt.Sprint(value)
+}
+
+var (
+	// TypeInt converts the value to an int64.
+	TypeInt FieldType = &intType{}
+	// TypeFloat converts the value to a float64.
+	TypeFloat FieldType = &floatType{}
+	// TypeBool converts the value to a bool.
+	TypeBool FieldType = &boolType{}
+	// TypeUUID converts the value to a UUID.
+	TypeUUID FieldType = &uuidType{}
+	// TypeDecimal converts the value to a Decimal.
+	TypeDecimal FieldType = &decimalType{}
+)
+
+// NewTimeType returns a type which parses the value to a time.Time with
+// the given layout.
+func NewTimeType(layout string) FieldType {
+	return &timeType{layout: layout}
+}
+
+// NewEnumType returns a type which only accepts the given values.
+func NewEnumType(values ...string) FieldType {
+	return &enumType{values: values}
+}
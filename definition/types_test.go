@@ -0,0 +1,78 @@
+package definition
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+var _ = Suite(&TypesTest{})
+
+type TypesTest struct{}
+
+func (t *TypesTest) convert(c *C, fieldType FieldType, value string) interface{} {
+	converted, err := fieldType.Convert(value)
+	c.Assert(err, IsNil)
+	return converted
+}
+
+func (t *TypesTest) expectError(c *C, fieldType FieldType, value string) {
+	_, err := fieldType.Convert(value)
+	c.Assert(err, NotNil)
+}
+
+func (t *TypesTest) TestInt(c *C) {
+	c.Assert(t.convert(c, TypeInt, "-12"), Equals, int64(-12))
+	t.expectError(c, TypeInt, "1.5")
+}
+
+func (t *TypesTest) TestFloat(c *C) {
+	c.Assert(t.convert(c, TypeFloat, "1.5"), Equals, 1.5)
+	t.expectError(c, TypeFloat, "abc")
+}
+
+func (t *TypesTest) TestBool(c *C) {
+	c.Assert(t.convert(c, TypeBool, "true"), Equals, true)
+	t.expectError(c, TypeBool, "yes")
+}
+
+func (t *TypesTest) TestUUID(c *C) {
+	c.Assert(
+		t.convert(c, TypeUUID, "6BA7B810-9DAD-11D1-80B4-00C04FD430C8"),
+		Equals,
+		UUID("6ba7b810-9dad-11d1-80b4-00c04fd430c8"),
+	)
+	t.expectError(c, TypeUUID, "6ba7b810")
+}
+
+func (t *TypesTest) TestDecimal(c *C) {
+	c.Assert(t.convert(c, TypeDecimal, "-10.50"), Equals, Decimal("-10.50"))
+	t.expectError(c, TypeDecimal, "1e5")
+}
+
+func (t *TypesTest) TestTime(c *C) {
+	converted := t.convert(c, NewTimeType("2006-01-02"), "2015-01-02")
+	c.Assert(converted, Equals, time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC))
+	t.expectError(c, NewTimeType("2006-01-02"), "02.01.2015")
+}
+
+func (t *TypesTest) TestEnum(c *C) {
+	enum := NewEnumType("active", "inactive")
+	c.Assert(t.convert(c, enum, "active"), Equals, "active")
+	t.expectError(c, enum, "deleted")
+	c.Assert(enum.Name(), Equals, "enum(active,inactive)")
+}
+
+func (t *TypesTest) TestFormat(c *C) {
+	for fieldType, raw := range map[FieldType]string{
+		TypeInt:                   "12",
+		TypeFloat:                 "1.25",
+		TypeBool:                  "true",
+		TypeUUID:                  "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		TypeDecimal:               "-10.50",
+		NewTimeType(time.RFC3339): "2015-01-02T10:00:00+02:00",
+		NewEnumType("a", "b"):     "b",
+	} {
+		c.Assert(fieldType.Format(t.convert(c, fieldType, raw)), Equals, raw)
+	}
+}
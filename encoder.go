@@ -0,0 +1,199 @@
+package filterparams
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+// Encode converts the query data back into url values. Parsing the
+// returned values with the query results in the same query data. A cursor
+// isn't encoded, use EncodeCursor for links to the next pages.
+func (q *Query) Encode(data *QueryData) (url.Values, error) {
+	values := url.Values{}
+	if data.filter != nil {
+		binding, err := encodeBinding(data.filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, parameter := range uniqueParameters(data.filter) {
+			if err := q.encodeParameter(values, parameter); err != nil {
+				return nil, err
+			}
+		}
+		values.Set("filter[binding]", binding)
+	}
+	for _, order := range data.forwardOrders() {
+		values.Add("filter[order]", encodeOrder(order))
+	}
+	for resourceType, fieldset := range data.GetFieldsets() {
+		values.Set("fields["+resourceType+"]", strings.Join(fieldset.Names(), ","))
+	}
+	if len(data.GetIncludes()) > 0 {
+		values.Set(includeKey, strings.Join(data.GetIncludes(), ","))
+	}
+	if page := data.GetPage(); page != nil {
+		values.Set(pageOffsetKey, strconv.Itoa(page.Offset))
+		if page.Limit > 0 {
+			values.Set(pageLimitKey, strconv.Itoa(page.Limit))
+		}
+	}
+	return values, nil
+}
+
+// EncodeQueryData converts the query data into url values with the default
+// configuration of a query. Values are formatted without any field types.
+func EncodeQueryData(data *QueryData) (url.Values, error) {
+	return newQuery(nil).Encode(data)
+}
+
+// encodeParameter adds the keys of the parameter to the values.
+func (q *Query) encodeParameter(values url.Values, parameter *definition.Parameter) error {
+	if parameter.Filter == nil {
+		return fmt.Errorf("Parameter %s has no filter", parameter.Identification)
+	}
+	key := fmt.Sprintf("filter[param][%s][%s]", parameter.Name, parameter.Filter.Identification)
+	if parameter.Identification != parameter.Name {
+		key = fmt.Sprintf("%s[%s]", key, parameter.Identification)
+	}
+	if parameter.Filter.Arity == definition.ArityNone {
+		values.Set(key, "")
+		return nil
+	}
+	fieldType := q.getFieldType(parameter.Name)
+	if parameter.Filter.Untyped {
+		fieldType = nil
+	}
+
+	if valueRange, ok := parameter.Value.(*definition.Range); ok {
+		values.Set(key, q.joinList([]string{
+			formatValue(valueRange.From, fieldType),
+			formatValue(valueRange.To, fieldType),
+		}))
+		return nil
+	}
+	reflected := reflect.ValueOf(parameter.Value)
+	if reflected.Kind() == reflect.Slice && reflected.Type().Elem().Kind() != reflect.Uint8 {
+		if reflected.Len() == 0 {
+			values.Set(key, "")
+			return nil
+		}
+		for index := 0; index < reflected.Len(); index++ {
+			values.Add(key+"[]", formatValue(reflected.Index(index).Interface(), fieldType))
+		}
+		return nil
+	}
+	values.Set(key, formatValue(parameter.Value, fieldType))
+	return nil
+}
+
+// joinList joins the items with the list separator and escapes them.
+func (q *Query) joinList(items []string) string {
+	separator, escape := string(q.getListSeparator()), string(q.getListEscape())
+	escaped := make([]string, len(items))
+	for index, item := range items {
+		item = strings.Replace(item, escape, escape+escape, -1)
+		escaped[index] = strings.Replace(item, separator, escape+separator, -1)
+	}
+	return strings.Join(escaped, separator)
+}
+
+// formatValue returns the raw representation of the value. Without a field
+// type times are formatted as RFC 3339.
+func formatValue(value interface{}, fieldType definition.FieldType) string {
+	if fieldType != nil {
+		return fieldType.Format(value)
+	}
+	if timeValue, ok := value.(time.Time); ok {
+		return timeValue.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(value)
+}
+
+// encodeOrder returns the filter[order] representation of the order.
+func encodeOrder(order *definition.Order) string {
+	direction := "asc"
+	if order.OrderDesc() {
+		direction = "desc"
+	}
+	switch order.GetNulls() {
+	case definition.NullsFirst:
+		return fmt.Sprintf("%s(%s,nullsfirst)", direction, order.GetOrderBy())
+	case definition.NullsLast:
+		return fmt.Sprintf("%s(%s,nullslast)", direction, order.GetOrderBy())
+	}
+	if order.OrderDesc() {
+		return fmt.Sprintf("desc(%s)", order.GetOrderBy())
+	}
+	return order.GetOrderBy()
+}
+
+// encodeBinding returns the filter[binding] representation of the node.
+func encodeBinding(node interface{}) (string, error) {
+	switch item := node.(type) {
+	case *definition.Parameter:
+		return item.Identification, nil
+	case *definition.Negate:
+		negated, err := encodeBindingOperand(item.Negated)
+		if err != nil {
+			return "", err
+		}
+		return "!" + negated, nil
+	case *definition.And:
+		return encodeBindingLeftRight(&item.LeftRight, "&")
+	case *definition.Or:
+		return encodeBindingLeftRight(&item.LeftRight, "|")
+	}
+	return "", fmt.Errorf("Unexpected node %T in filter", node)
+}
+
+// encodeBindingLeftRight joins both sides of the node with the operator.
+func encodeBindingLeftRight(node *definition.LeftRight, operator string) (string, error) {
+	left, err := encodeBindingOperand(node.Left)
+	if err != nil {
+		return "", err
+	}
+	right, err := encodeBindingOperand(node.Right)
+	if err != nil {
+		return "", err
+	}
+	return left + operator + right, nil
+}
+
+// encodeBindingOperand encodes the node and encloses it in brackets if
+// it combines multiple nodes.
+func encodeBindingOperand(node interface{}) (string, error) {
+	encoded, err := encodeBinding(node)
+	if err != nil {
+		return "", err
+	}
+	switch node.(type) {
+	case *definition.And, *definition.Or:
+		return "(" + encoded + ")", nil
+	}
+	return encoded, nil
+}
+
+// uniqueParameters returns the parameters of the tree with every
+// identification only once.
+func uniqueParameters(node interface{}) []*definition.Parameter {
+	haver, ok := node.(definition.ParameterHaver)
+	if !ok {
+		return []*definition.Parameter{}
+	}
+	seen := map[string]bool{}
+	parameters := []*definition.Parameter{}
+	for _, parameter := range haver.GetParameters() {
+		if seen[parameter.Identification] {
+			continue
+		}
+		seen[parameter.Identification] = true
+		parameters = append(parameters, parameter)
+	}
+	return parameters
+}
@@ -0,0 +1,103 @@
+package filterparams
+
+import (
+	"net/url"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+var _ = Suite(&EncoderTest{})
+
+type EncoderTest struct {
+	builder *QueryBuilder
+}
+
+func (t *EncoderTest) SetUpTest(c *C) {
+	t.builder = NewBuilder()
+	t.builder.EnableFilter(definition.FilterEq)
+	t.builder.EnableFilter(definition.FilterLike)
+	t.builder.EnableFilter(definition.FilterIn)
+	t.builder.EnableFilter(definition.FilterGt)
+}
+
+// assertRoundTrip parses the values, encodes the result and verifies that
+// parsing the encoded values results in the same query data.
+func (t *EncoderTest) assertRoundTrip(c *C, values url.Values) url.Values {
+	query := t.builder.CreateQuery()
+	queryData, err := query.Parse(&values)
+	c.Assert(err, IsNil)
+	encoded, err := query.Encode(queryData)
+	c.Assert(err, IsNil)
+	reparsed, err := query.Parse(&encoded)
+	c.Assert(err, IsNil)
+	c.Assert(reparsed, DeepEquals, queryData)
+	return encoded
+}
+
+func (t *EncoderTest) TestEncode(c *C) {
+	encoded := t.assertRoundTrip(c, url.Values{
+		"filter[param][name][eq][aliasedName]": {"smith"},
+		"filter[param][date]":                  {"2015-01-01"},
+		"filter[binding]":                      {"aliasedName|!date"},
+		"filter[order]":                        {"desc(date)"},
+	})
+	c.Assert(encoded, DeepEquals, url.Values{
+		"filter[param][name][eq][aliasedName]": {"smith"},
+		"filter[param][date][eq]":              {"2015-01-01"},
+		"filter[binding]":                      {"aliasedName|!date"},
+		"filter[order]":                        {"desc(date)"},
+	})
+}
+
+func (t *EncoderTest) TestEncodeList(c *C) {
+	encoded := t.assertRoundTrip(c, url.Values{"filter[param][name][in]": {"smith,doe\\,john"}})
+	c.Assert(encoded["filter[param][name][in][]"], DeepEquals, []string{"smith", "doe,john"})
+}
+
+func (t *EncoderTest) TestRoundTripTyped(c *C) {
+	t.builder.SetFieldType("age", definition.TypeInt)
+	t.builder.SetFieldType("created", definition.NewTimeType(time.RFC3339))
+	t.assertRoundTrip(c, url.Values{
+		"filter[param][age][in]":      {"18,21"},
+		"filter[param][created][gt]":  {"2015-01-02T10:00:00+02:00"},
+		"filter[param][age][eq][old]": {"99"},
+		"filter[binding]":             {"(age|old)&created"},
+	})
+}
+
+func (t *EncoderTest) TestRoundTripRange(c *C) {
+	t.builder.EnableFilter(&definition.Filter{Identification: "between", Arity: definition.ArityRange})
+	encoded := t.assertRoundTrip(c, url.Values{"filter[param][code][between]": {"a\\,b,c"}})
+	c.Assert(encoded.Get("filter[param][code][between]"), Equals, "a\\,b,c")
+}
+
+func (t *EncoderTest) TestEncodeQueryData(c *C) {
+	parameter := definition.NewParameter("age")
+	parameter.Name = "age"
+	parameter.Filter = definition.FilterGt
+	parameter.Value = 18
+	encoded, err := EncodeQueryData(NewQueryData(parameter, []*definition.Order{definition.NewOrderAsc("age")}))
+	c.Assert(err, IsNil)
+	c.Assert(encoded, DeepEquals, url.Values{
+		"filter[param][age][gt]": {"18"},
+		"filter[binding]":        {"age"},
+		"filter[order]":          {"age"},
+	})
+}
+
+func (t *EncoderTest) TestRoundTripOrderNulls(c *C) {
+	encoded := t.assertRoundTrip(c, url.Values{
+		"filter[order]": {"desc(name,nullslast)", "asc(age,nullsfirst)", "desc(created)", "id"},
+	})
+	c.Assert(encoded["filter[order]"], DeepEquals, []string{"desc(name,nullslast)", "asc(age,nullsfirst)", "desc(created)", "id"})
+}
+
+func (t *EncoderTest) TestRoundTripPage(c *C) {
+	t.builder.EnablePagination(20, 100)
+	encoded := t.assertRoundTrip(c, url.Values{"page[number]": {"3"}, "page[size]": {"10"}})
+	c.Assert(encoded.Get("page[offset]"), Equals, "20")
+	c.Assert(encoded.Get("page[limit]"), Equals, "10")
+}
@@ -2,16 +2,106 @@ package filterparams
 
 import (
 	"fmt"
+	"strings"
+
+	"github.com/cbrand/go-filterparams/binding"
 )
 
+// KeyedError is implemented by all errors returned while parsing which can be
+// attributed to a query parameter.
+type KeyedError interface {
+	error
+	// QueryKey returns the query parameter which caused the error, e.g.
+	// "filter[param][name][eq]".
+	QueryKey() string
+}
+
+// errorKey is embedded in the parse errors to implement KeyedError.
+type errorKey struct {
+	// Key is the query parameter which caused the error.
+	Key string
+}
+
+// QueryKey returns the query parameter which caused the error.
+func (e *errorKey) QueryKey() string {
+	return e.Key
+}
+
+// setQueryKey sets the query parameter which caused the error.
+func (e *errorKey) setQueryKey(key string) {
+	e.Key = key
+}
+
+// ParseErrors is returned by queries which collect errors and lists all
+// problems of the parsed parameters.
+type ParseErrors []error
+
+// Error returns the messages of all errors.
+func (p ParseErrors) Error() string {
+	messages := make([]string, len(p))
+	for index, err := range p {
+		messages[index] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// errorCollector gathers the errors while parsing.
+type errorCollector struct {
+	collect bool
+	errors  []error
+}
+
+// add records the error for the given query key. Returns if parsing should
+// stop because errors aren't collected.
+func (e *errorCollector) add(err error, key string) bool {
+	if keyed, ok := err.(interface {
+		QueryKey() string
+		setQueryKey(string)
+	}); ok && len(keyed.QueryKey()) == 0 {
+		keyed.setQueryKey(key)
+	}
+	e.errors = append(e.errors, err)
+	return e.stopped()
+}
+
+// stopped returns if an error occurred and parsing should stop.
+func (e *errorCollector) stopped() bool {
+	return !e.collect && len(e.errors) > 0
+}
+
+// err returns the collected errors. If errors aren't collected the first
+// error is returned.
+func (e *errorCollector) err() error {
+	if len(e.errors) == 0 {
+		return nil
+	}
+	if !e.collect {
+		return e.errors[0]
+	}
+	return ParseErrors(e.errors)
+}
+
+// newErrorCollector returns a collector which collects all errors if
+// collect is set.
+func newErrorCollector(collect bool) *errorCollector {
+	return &errorCollector{collect: collect}
+}
+
 // UnsupportedOperationError indicates that an operation was passed
 // which is unsupported.
 type UnsupportedOperationError struct {
+	errorKey
 	Operation string
+	// Field is the name of the field the operation has been requested
+	// for. It is empty if the error isn't related to a field.
+	Field string
 }
 
 // Error returns the formatted error message.
 func (u *UnsupportedOperationError) Error() string {
+	if len(u.Field) > 0 {
+		return fmt.Sprintf("The operation \"%s\" is unsupported for the field \"%s\"", u.Operation, u.Field)
+	}
 	return fmt.Sprintf("The operation \"%s\" is unsupported", u.Operation)
 }
 
@@ -22,3 +112,193 @@ func NewUnsupportedOperation(operation string) *UnsupportedOperationError {
 		Operation: operation,
 	}
 }
+
+// NewUnsupportedFieldOperation generates the error with the passed field
+// and operation as indication.
+func NewUnsupportedFieldOperation(field, operation string) *UnsupportedOperationError {
+	return &UnsupportedOperationError{
+		Operation: operation,
+		Field:     field,
+	}
+}
+
+// ValueConversionError indicates that the value of a parameter could not
+// be converted to the type of its field.
+type ValueConversionError struct {
+	errorKey
+	// Parameter is the name of the parameter.
+	Parameter string
+	// Value is the raw value which has been passed.
+	Value string
+	// ExpectedType is the name of the type of the field.
+	ExpectedType string
+	// Err is the error returned by the conversion.
+	Err error
+}
+
+// Error returns the formatted error message.
+func (v *ValueConversionError) Error() string {
+	return fmt.Sprintf("The value \"%s\" of parameter \"%s\" is not of type %s", v.Value, v.Parameter, v.ExpectedType)
+}
+
+// Unwrap returns the error returned by the conversion.
+func (v *ValueConversionError) Unwrap() error {
+	return v.Err
+}
+
+// NewValueConversionError generates the error for the passed parameter.
+func NewValueConversionError(parameter, value, expectedType string, err error) *ValueConversionError {
+	return &ValueConversionError{
+		Parameter:    parameter,
+		Value:        value,
+		ExpectedType: expectedType,
+		Err:          err,
+	}
+}
+
+// UnknownFieldError indicates that a parameter has been passed for a
+// field which has not been registered.
+type UnknownFieldError struct {
+	errorKey
+	Field string
+}
+
+// Error returns the formatted error message.
+func (u *UnknownFieldError) Error() string {
+	return fmt.Sprintf("The field \"%s\" is unknown", u.Field)
+}
+
+// NewUnknownFieldError generates the error for the passed field name.
+func NewUnknownFieldError(field string) *UnknownFieldError {
+	return &UnknownFieldError{
+		Field: field,
+	}
+}
+
+// UnknownResourceTypeError indicates that a sparse fieldset has been
+// requested for a resource type which isn't part of the schema.
+type UnknownResourceTypeError struct {
+	errorKey
+	ResourceType string
+}
+
+// Error returns the formatted error message.
+func (u *UnknownResourceTypeError) Error() string {
+	return fmt.Sprintf("The resource type \"%s\" is unknown", u.ResourceType)
+}
+
+// NewUnknownResourceTypeError generates the error for the passed type.
+func NewUnknownResourceTypeError(resourceType string) *UnknownResourceTypeError {
+	return &UnknownResourceTypeError{
+		ResourceType: resourceType,
+	}
+}
+
+// UnknownRelationshipError indicates that an include path references a
+// relationship which has not been registered.
+type UnknownRelationshipError struct {
+	errorKey
+	Relationship string
+}
+
+// Error returns the formatted error message.
+func (u *UnknownRelationshipError) Error() string {
+	return fmt.Sprintf("The relationship \"%s\" is unknown", u.Relationship)
+}
+
+// NewUnknownRelationshipError generates the error for the passed
+// relationship path.
+func NewUnknownRelationshipError(relationship string) *UnknownRelationshipError {
+	return &UnknownRelationshipError{
+		Relationship: relationship,
+	}
+}
+
+// InvalidNameError indicates that a parameter, alias or order name contains
+// characters which aren't allowed.
+type InvalidNameError struct {
+	errorKey
+	Name string
+}
+
+// Error returns the formatted error message.
+func (i *InvalidNameError) Error() string {
+	return fmt.Sprintf("The name \"%s\" is invalid", i.Name)
+}
+
+// NewInvalidNameError generates the error for the passed name.
+func NewInvalidNameError(name string) *InvalidNameError {
+	return &InvalidNameError{
+		Name: name,
+	}
+}
+
+// InvalidOrderError indicates that an order doesn't follow the order
+// syntax, e.g. because of an unknown option.
+type InvalidOrderError struct {
+	errorKey
+	Order string
+}
+
+// Error returns the formatted error message.
+func (i *InvalidOrderError) Error() string {
+	return fmt.Sprintf("The order \"%s\" is invalid", i.Order)
+}
+
+// NewInvalidOrderError generates the error for the passed order.
+func NewInvalidOrderError(order string) *InvalidOrderError {
+	return &InvalidOrderError{
+		Order: order,
+	}
+}
+
+// UnknownOrderError indicates that an order has been requested for a
+// field which has not been registered.
+type UnknownOrderError struct {
+	errorKey
+	OrderBy string
+}
+
+// Error returns the formatted error message.
+func (u *UnknownOrderError) Error() string {
+	return fmt.Sprintf("Ordering by \"%s\" is not supported", u.OrderBy)
+}
+
+// NewUnknownOrderError generates the error for the passed order name.
+func NewUnknownOrderError(orderBy string) *UnknownOrderError {
+	return &UnknownOrderError{
+		OrderBy: orderBy,
+	}
+}
+
+// BindingSyntaxError indicates that the filter[binding] parameter could not
+// be parsed.
+type BindingSyntaxError struct {
+	errorKey
+	// Position is the position at which the binding became invalid.
+	binding.Position
+	// Binding is the passed binding string.
+	Binding string
+	// Expected lists the tokens which would have been valid at the
+	// position.
+	Expected []string
+}
+
+// Error returns the formatted error message.
+func (b *BindingSyntaxError) Error() string {
+	message := fmt.Sprintf("The binding \"%s\" is invalid at line %d, column %d", b.Binding, b.Line, b.Column)
+	if len(b.Expected) > 0 {
+		message = fmt.Sprintf("%s, expected one of %s", message, strings.Join(b.Expected, ", "))
+	}
+	return message
+}
+
+// NewBindingSyntaxError generates the error from the syntax error of the
+// binding parser.
+func NewBindingSyntaxError(syntaxErr *binding.SyntaxError) *BindingSyntaxError {
+	return &BindingSyntaxError{
+		Position: syntaxErr.Position,
+		Binding:  syntaxErr.Input,
+		Expected: syntaxErr.Expected,
+	}
+}
@@ -0,0 +1,173 @@
+package filterparams
+
+import (
+	"net/url"
+	"sort"
+
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+const includeKey = "include"
+
+// Fieldset is the sparse fieldset which has been requested for a resource
+// type with fields[type].
+type Fieldset struct {
+	// ResourceType is the JSON:API type of the resources.
+	ResourceType string
+	// Fields are the requested fields in the order of the query.
+	Fields []*definition.Field
+	// Relationships are the requested relationships in the order of the
+	// query.
+	Relationships []*definition.Relationship
+}
+
+// Names returns the names of the requested fields followed by the names of
+// the requested relationships.
+func (f *Fieldset) Names() []string {
+	names := make([]string, 0, len(f.Fields)+len(f.Relationships))
+	for _, field := range f.Fields {
+		names = append(names, field.Name)
+	}
+	for _, relationship := range f.Relationships {
+		names = append(names, relationship.Name)
+	}
+	return names
+}
+
+// Has returns if the field or relationship with the given name has been
+// requested.
+func (f *Fieldset) Has(name string) bool {
+	for _, existing := range f.Names() {
+		if existing == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceSchema lists the fields and relationships of a resource type.
+type resourceSchema struct {
+	fields        []*definition.Field
+	relationships []*definition.Relationship
+}
+
+// getField returns the field with the given name or nil.
+func (r *resourceSchema) getField(name string) *definition.Field {
+	for _, field := range r.fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// schemas returns the schemas of all resource types reachable from the
+// resource of the query keyed by their type. Types which are related
+// multiple times combine the fields and relationships of all occurrences.
+func (q *Query) schemas() map[string]*resourceSchema {
+	schemas := map[string]*resourceSchema{}
+	addSchema(schemas, map[*definition.Relationship]bool{}, q.resourceType, q.fields, q.relationships)
+	return schemas
+}
+
+// addSchema adds the fields and relationships to the schema of the
+// resource type and recurses into the relationships which haven't been
+// visited yet.
+func addSchema(schemas map[string]*resourceSchema, visited map[*definition.Relationship]bool, resourceType string, fields []*definition.Field, relationships []*definition.Relationship) {
+	schema, ok := schemas[resourceType]
+	if !ok {
+		schema = &resourceSchema{}
+		schemas[resourceType] = schema
+	}
+	for _, field := range fields {
+		if schema.getField(field.Name) == nil {
+			schema.fields = append(schema.fields, field)
+		}
+	}
+	for _, relationship := range relationships {
+		if definition.GetRelationship(schema.relationships, relationship.Name) == nil {
+			schema.relationships = append(schema.relationships, relationship)
+		}
+		if visited[relationship] {
+			continue
+		}
+		visited[relationship] = true
+		addSchema(schemas, visited, relationship.GetResourceType(), relationship.Fields, relationship.Relationships)
+	}
+}
+
+// parseFieldsets parses the fields[type] parameters. Errors are added to
+// the collector.
+func (q *Query) parseFieldsets(values *url.Values, errs *errorCollector) map[string]*Fieldset {
+	keys := make([]string, 0, len(*values))
+	for key := range *values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	schemas := q.schemas()
+	fieldsets := map[string]*Fieldset{}
+	for _, key := range keys {
+		prefix, segments, ok := splitKey(key)
+		if !ok || prefix != "fields" || len(segments) != 1 {
+			continue
+		}
+		resourceType := segments[0]
+		schema, ok := schemas[resourceType]
+		if !ok {
+			if errs.add(NewUnknownResourceTypeError(resourceType), key) {
+				return nil
+			}
+			continue
+		}
+		fieldset := &Fieldset{
+			ResourceType:  resourceType,
+			Fields:        []*definition.Field{},
+			Relationships: []*definition.Relationship{},
+		}
+		for _, name := range commaEntries((*values)[key]) {
+			if fieldset.Has(name) {
+				continue
+			}
+			if field := schema.getField(name); field != nil {
+				fieldset.Fields = append(fieldset.Fields, field)
+			} else if relationship := definition.GetRelationship(schema.relationships, name); relationship != nil {
+				fieldset.Relationships = append(fieldset.Relationships, relationship)
+			} else if errs.add(NewUnknownFieldError(name), key) {
+				return nil
+			}
+		}
+		fieldsets[resourceType] = fieldset
+	}
+	return fieldsets
+}
+
+// parseIncludes parses the relationship paths of the include parameter.
+// Repeated paths are removed. Errors are added to the collector.
+func (q *Query) parseIncludes(values *url.Values, errs *errorCollector) []string {
+	includes := []string{}
+	seen := map[string]bool{}
+	for _, path := range commaEntries((*values)[includeKey]) {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		known, relationships := true, q.relationships
+		for _, segment := range definition.SplitPath(path) {
+			relationship := definition.GetRelationship(relationships, segment)
+			if relationship == nil {
+				known = false
+				break
+			}
+			relationships = relationship.Relationships
+		}
+		if !known {
+			if errs.add(NewUnknownRelationshipError(path), includeKey) {
+				return nil
+			}
+			continue
+		}
+		includes = append(includes, path)
+	}
+	return includes
+}
@@ -0,0 +1,151 @@
+package filterparams
+
+import (
+	"net/url"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+var _ = Suite(&FieldsetTest{})
+
+type FieldsetTest struct {
+	builder *QueryBuilder
+	data    *url.Values
+}
+
+func (t *FieldsetTest) SetUpTest(c *C) {
+	author := definition.NewRelationship("author", "people", "author_id", "id").
+		SetResourceType("people").
+		EnableField("name", "full_name").
+		EnableField("email", "")
+	commenter := definition.NewRelationship("author", "people", "commenter_id", "id").
+		SetResourceType("people").
+		EnableField("age", "")
+	comments := definition.NewRelationship("comments", "comments", "id", "article_id").
+		EnableField("body", "").
+		AddRelationship(commenter)
+	t.builder = NewBuilder().
+		EnableFilter(definition.FilterEq).
+		SetResourceType("articles").
+		EnableField("title", "").
+		EnableField("body", "content").
+		AddRelationship(author).
+		AddRelationship(comments)
+	t.data = &url.Values{}
+}
+
+func (t *FieldsetTest) run(c *C) *QueryData {
+	queryData, err := t.builder.CreateQuery().Parse(t.data)
+	c.Assert(err, IsNil)
+	return queryData
+}
+
+func (t *FieldsetTest) TestDisabled(c *C) {
+	t.builder.SetResourceType("")
+	t.data.Set("fields[articles]", "unknown")
+	t.data.Set("include", "unknown")
+	queryData := t.run(c)
+	c.Assert(queryData.GetFieldsets(), IsNil)
+	c.Assert(queryData.GetIncludes(), IsNil)
+}
+
+func (t *FieldsetTest) TestNoFieldsets(c *C) {
+	queryData := t.run(c)
+	c.Assert(queryData.GetResourceType(), Equals, "articles")
+	c.Assert(queryData.GetFieldset("articles"), IsNil)
+	c.Assert(queryData.GetIncludes(), HasLen, 0)
+}
+
+func (t *FieldsetTest) TestFieldsets(c *C) {
+	t.data.Set("fields[articles]", "body, author,title,body")
+	t.data.Set("fields[people]", "name,age")
+	t.data.Set("fields[comments]", "")
+	queryData := t.run(c)
+
+	articles := queryData.GetFieldset("articles")
+	c.Assert(articles.ResourceType, Equals, "articles")
+	c.Assert(articles.Names(), DeepEquals, []string{"body", "title", "author"})
+	c.Assert(articles.Fields[0].BackendName, Equals, "content")
+	c.Assert(articles.Relationships[0].LocalKey, Equals, "author_id")
+	c.Assert(articles.Has("author"), Equals, true)
+	c.Assert(articles.Has("comments"), Equals, false)
+
+	c.Assert(queryData.GetFieldset("people").Names(), DeepEquals, []string{"name", "age"})
+	c.Assert(queryData.GetFieldset("comments").Names(), HasLen, 0)
+	c.Assert(queryData.GetFieldsets(), HasLen, 3)
+}
+
+func (t *FieldsetTest) TestUnknownField(c *C) {
+	t.data.Set("fields[people]", "name,title")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	fieldErr, ok := err.(*UnknownFieldError)
+	c.Assert(ok, Equals, true, Commentf("Unexpected error %v", err))
+	c.Assert(fieldErr.Field, Equals, "title")
+	c.Assert(fieldErr.QueryKey(), Equals, "fields[people]")
+}
+
+func (t *FieldsetTest) TestUnknownResourceType(c *C) {
+	t.data.Set("fields[tags]", "label")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	typeErr, ok := err.(*UnknownResourceTypeError)
+	c.Assert(ok, Equals, true, Commentf("Unexpected error %v", err))
+	c.Assert(typeErr.ResourceType, Equals, "tags")
+	c.Assert(typeErr.QueryKey(), Equals, "fields[tags]")
+}
+
+func (t *FieldsetTest) TestIncludes(c *C) {
+	t.data.Add("include", "comments.author,author")
+	t.data.Add("include", "author")
+	c.Assert(t.run(c).GetIncludes(), DeepEquals, []string{"comments.author", "author"})
+}
+
+func (t *FieldsetTest) TestUnknownInclude(c *C) {
+	for _, include := range []string{"tags", "author.comments", "comments.body"} {
+		t.data.Set("include", include)
+		_, err := t.builder.CreateQuery().Parse(t.data)
+		includeErr, ok := err.(*UnknownRelationshipError)
+		c.Assert(ok, Equals, true, Commentf("Unexpected error %v for %s", err, include))
+		c.Assert(includeErr.Relationship, Equals, include)
+		c.Assert(includeErr.QueryKey(), Equals, "include")
+	}
+}
+
+func (t *FieldsetTest) TestCollectErrors(c *C) {
+	t.builder.SetCollectErrors(true)
+	t.data.Set("fields[articles]", "unknown,title,missing")
+	t.data.Set("fields[tags]", "label")
+	t.data.Set("include", "tags,author")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	parseErrs, ok := err.(ParseErrors)
+	c.Assert(ok, Equals, true)
+	c.Assert(parseErrs, HasLen, 4)
+}
+
+func (t *FieldsetTest) TestRoundTrip(c *C) {
+	t.data.Set("fields[articles]", "title,author")
+	t.data.Set("fields[people]", "email")
+	t.data.Set("include", "author,comments.author")
+	query := t.builder.CreateQuery()
+	queryData, err := query.Parse(t.data)
+	c.Assert(err, IsNil)
+	encoded, err := query.Encode(queryData)
+	c.Assert(err, IsNil)
+	c.Assert(encoded.Get("fields[articles]"), Equals, "title,author")
+	c.Assert(encoded.Get("include"), Equals, "author,comments.author")
+	reparsed, err := query.Parse(&encoded)
+	c.Assert(err, IsNil)
+	c.Assert(reparsed, DeepEquals, queryData)
+
+	fingerprint, err := queryData.Fingerprint()
+	c.Assert(err, IsNil)
+	t.data.Set("include", "comments.author,author")
+	other, err := t.run(c).Fingerprint()
+	c.Assert(err, IsNil)
+	c.Assert(other, Equals, fingerprint)
+	t.data.Set("include", "author")
+	other, err = t.run(c).Fingerprint()
+	c.Assert(err, IsNil)
+	c.Assert(other, Not(Equals), fingerprint)
+}
@@ -0,0 +1,135 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/cbrand/go-filterparams"
+)
+
+// ContentType is the media type of JSON:API documents.
+const ContentType = "application/vnd.api+json"
+
+// ErrorSource references the part of the request which caused the error.
+type ErrorSource struct {
+	// Parameter is the query parameter which caused the error.
+	Parameter string `json:"parameter,omitempty"`
+}
+
+// ErrorObject is a JSON:API error object.
+type ErrorObject struct {
+	Status string                 `json:"status"`
+	Code   string                 `json:"code"`
+	Title  string                 `json:"title"`
+	Detail string                 `json:"detail"`
+	Source *ErrorSource           `json:"source,omitempty"`
+	Meta   map[string]interface{} `json:"meta,omitempty"`
+}
+
+// ErrorDocument is the top level JSON:API document containing errors.
+type ErrorDocument struct {
+	Errors []*ErrorObject `json:"errors"`
+}
+
+// NewErrorObject converts an error returned by Query.Parse into a JSON:API
+// error object.
+func NewErrorObject(err error) *ErrorObject {
+	object := &ErrorObject{
+		Status: strconv.Itoa(http.StatusBadRequest),
+		Code:   "invalid_query",
+		Title:  "Invalid query parameter",
+		Detail: err.Error(),
+	}
+	switch typedErr := err.(type) {
+	case *filterparams.UnsupportedOperationError:
+		object.Code = "unsupported_operation"
+		object.Title = "Unsupported filter operation"
+	case *filterparams.UnknownFieldError:
+		object.Code = "unknown_field"
+		object.Title = "Unknown filter field"
+	case *filterparams.UnknownResourceTypeError:
+		object.Code = "unknown_resource_type"
+		object.Title = "Unknown resource type"
+	case *filterparams.UnknownRelationshipError:
+		object.Code = "unknown_relationship"
+		object.Title = "Unknown relationship"
+	case *filterparams.InvalidNameError:
+		object.Code = "invalid_name"
+		object.Title = "Invalid filter name"
+	case *filterparams.InvalidOrderError:
+		object.Code = "invalid_order"
+		object.Title = "Invalid filter order"
+	case *filterparams.UnknownOrderError:
+		object.Code = "unknown_order"
+		object.Title = "Unknown order field"
+	case *filterparams.InvalidPageError:
+		object.Code = "invalid_page"
+		object.Title = "Invalid page parameter"
+	case *filterparams.InvalidCursorError:
+		object.Code = "invalid_cursor"
+		object.Title = "Invalid page cursor"
+	case *filterparams.ValueConversionError:
+		object.Code = "invalid_value"
+		object.Title = "Invalid filter value"
+	case *filterparams.BindingSyntaxError:
+		object.Code = "invalid_binding"
+		object.Title = "Invalid filter binding"
+		object.Meta = map[string]interface{}{
+			"offset":   typedErr.Offset,
+			"line":     typedErr.Line,
+			"column":   typedErr.Column,
+			"expected": typedErr.Expected,
+		}
+	case *filterparams.ParamNotFoundError:
+		object.Code = "parameter_not_found"
+		object.Title = "Unknown parameter in filter binding"
+		object.Meta = map[string]interface{}{
+			"offset": typedErr.Position.Offset,
+			"line":   typedErr.Position.Line,
+			"column": typedErr.Position.Column,
+		}
+	case *filterparams.LimitExceededError:
+		object.Code = "limit_exceeded"
+		object.Title = "Filter limit exceeded"
+		object.Meta = map[string]interface{}{
+			"limit":  string(typedErr.Limit),
+			"max":    typedErr.Max,
+			"actual": typedErr.Actual,
+		}
+	}
+	if keyed, ok := err.(filterparams.KeyedError); ok && len(keyed.QueryKey()) > 0 {
+		object.Source = &ErrorSource{Parameter: keyed.QueryKey()}
+	}
+	return object
+}
+
+// NewErrorDocument converts an error returned by Query.Parse into a JSON:API
+// document. ParseErrors result in one error object per collected error.
+func NewErrorDocument(err error) *ErrorDocument {
+	errs := []error{err}
+	if parseErrs, ok := err.(filterparams.ParseErrors); ok {
+		errs = parseErrs
+	}
+	document := &ErrorDocument{Errors: make([]*ErrorObject, len(errs))}
+	for index, entry := range errs {
+		document.Errors[index] = NewErrorObject(entry)
+	}
+	return document
+}
+
+// WriteError writes the error document of the error with the status
+// 400 Bad Request.
+func WriteError(w http.ResponseWriter, err error) error {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(http.StatusBadRequest)
+	return json.NewEncoder(w).Encode(NewErrorDocument(err))
+}
+
+// ErrorHandler returns a handler which responds with the error document
+// of the error.
+func ErrorHandler(err error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, err)
+	})
+}
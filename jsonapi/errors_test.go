@@ -0,0 +1,134 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/cbrand/go-filterparams"
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+var _ = Suite(&ErrorsTest{})
+
+type ErrorsTest struct {
+	builder *filterparams.QueryBuilder
+	data    *url.Values
+}
+
+func (t *ErrorsTest) SetUpTest(c *C) {
+	t.builder = filterparams.NewBuilder()
+	t.builder.EnableFilter(definition.FilterEq)
+	t.data = &url.Values{}
+}
+
+func (t *ErrorsTest) parseError(c *C) error {
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	c.Assert(err, NotNil)
+	return err
+}
+
+func (t *ErrorsTest) TestUnsupportedOperation(c *C) {
+	t.data.Set("filter[param][name][like]", "jo%")
+	object := NewErrorObject(t.parseError(c))
+	c.Assert(object.Status, Equals, "400")
+	c.Assert(object.Code, Equals, "unsupported_operation")
+	c.Assert(object.Detail, Equals, "The operation \"like\" is unsupported for the field \"name\"")
+	c.Assert(object.Source, DeepEquals, &ErrorSource{Parameter: "filter[param][name][like]"})
+}
+
+func (t *ErrorsTest) TestParamNotFound(c *C) {
+	t.data.Set("filter[param][name]", "doe")
+	t.data.Set("filter[binding]", "name|email")
+	object := NewErrorObject(t.parseError(c))
+	c.Assert(object.Code, Equals, "parameter_not_found")
+	c.Assert(object.Source.Parameter, Equals, "filter[binding]")
+	c.Assert(object.Meta["offset"], Equals, 5)
+}
+
+func (t *ErrorsTest) TestInvalidName(c *C) {
+	t.data.Set("filter[param][na$me]", "doe")
+	object := NewErrorObject(t.parseError(c))
+	c.Assert(object.Code, Equals, "invalid_name")
+	c.Assert(object.Source.Parameter, Equals, "filter[param][na$me]")
+}
+
+func (t *ErrorsTest) TestLimitExceeded(c *C) {
+	t.builder.SetMaxBindingLength(4)
+	t.data.Set("filter[param][name]", "doe")
+	t.data.Set("filter[binding]", "(name)")
+	object := NewErrorObject(t.parseError(c))
+	c.Assert(object.Code, Equals, "limit_exceeded")
+	c.Assert(object.Source.Parameter, Equals, "filter[binding]")
+	c.Assert(object.Meta["limit"], Equals, "binding length")
+	c.Assert(object.Meta["max"], Equals, 4)
+	c.Assert(object.Meta["actual"], Equals, 6)
+}
+
+func (t *ErrorsTest) TestInvalidPage(c *C) {
+	t.builder.EnablePagination(10, 50)
+	t.data.Set("page[number]", "first")
+	object := NewErrorObject(t.parseError(c))
+	c.Assert(object.Code, Equals, "invalid_page")
+	c.Assert(object.Source.Parameter, Equals, "page[number]")
+}
+
+func (t *ErrorsTest) TestInvalidCursor(c *C) {
+	t.builder.EnableCursorPagination("id", []byte("0123456789abcdef0123456789abcdef"))
+	t.data.Set("page[before]", "e30.c2lnbmF0dXJl")
+	object := NewErrorObject(t.parseError(c))
+	c.Assert(object.Code, Equals, "invalid_cursor")
+	c.Assert(object.Source.Parameter, Equals, "page[before]")
+}
+
+func (t *ErrorsTest) TestUnknownRelationship(c *C) {
+	author := definition.NewRelationship("author", "people", "author_id", "id").SetResourceType("people").EnableField("name", "")
+	t.builder.SetResourceType("articles").AddRelationship(author)
+	t.data.Set("include", "author,comments")
+	object := NewErrorObject(t.parseError(c))
+	c.Assert(object.Code, Equals, "unknown_relationship")
+	c.Assert(object.Source.Parameter, Equals, "include")
+
+	t.data.Del("include")
+	t.data.Set("fields[people]", "name")
+	t.data.Set("fields[tags]", "label")
+	object = NewErrorObject(t.parseError(c))
+	c.Assert(object.Code, Equals, "unknown_resource_type")
+	c.Assert(object.Source.Parameter, Equals, "fields[tags]")
+}
+
+func (t *ErrorsTest) TestUnknownError(c *C) {
+	object := NewErrorObject(errors.New("broken"))
+	c.Assert(object.Code, Equals, "invalid_query")
+	c.Assert(object.Source, IsNil)
+}
+
+func (t *ErrorsTest) TestDocumentWithParseErrors(c *C) {
+	t.builder.SetCollectErrors(true)
+	t.data.Set("filter[param][name][like]", "jo%")
+	t.data.Set("filter[param][email][gt]", "a")
+	document := NewErrorDocument(t.parseError(c))
+	c.Assert(len(document.Errors), Equals, 2)
+	c.Assert(document.Errors[0].Source.Parameter, Equals, "filter[param][email][gt]")
+	c.Assert(document.Errors[1].Source.Parameter, Equals, "filter[param][name][like]")
+}
+
+func (t *ErrorsTest) TestErrorHandler(c *C) {
+	t.data.Set("filter[param][name]", "doe")
+	t.data.Set("filter[binding]", "name|(")
+	recorder := httptest.NewRecorder()
+	ErrorHandler(t.parseError(c)).ServeHTTP(recorder, httptest.NewRequest("GET", "/users", nil))
+	c.Assert(recorder.Code, Equals, 400)
+	c.Assert(recorder.Header().Get("Content-Type"), Equals, ContentType)
+
+	document := map[string][]map[string]interface{}{}
+	c.Assert(json.Unmarshal(recorder.Body.Bytes(), &document), IsNil)
+	c.Assert(len(document["errors"]), Equals, 1)
+	errorObject := document["errors"][0]
+	c.Assert(errorObject["code"], Equals, "invalid_binding")
+	c.Assert(errorObject["source"], DeepEquals, map[string]interface{}{"parameter": "filter[binding]"})
+	c.Assert(errorObject["meta"].(map[string]interface{})["column"], Equals, float64(7))
+}
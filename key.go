@@ -0,0 +1,62 @@
+package filterparams
+
+import (
+	"strings"
+)
+
+// splitKey tokenizes a query key like "filter[param][name][eq]" into the
+// prefix in front of the first bracket and the contents of the brackets.
+// Returns false if the key isn't a prefix followed by bracket segments.
+func splitKey(key string) (string, []string, bool) {
+	start := strings.IndexByte(key, '[')
+	if start <= 0 {
+		return "", nil, false
+	}
+	segments := []string{}
+	remaining := key[start:]
+	for len(remaining) > 0 {
+		if remaining[0] != '[' {
+			return "", nil, false
+		}
+		end := strings.IndexByte(remaining, ']')
+		if end == -1 || strings.IndexByte(remaining[1:end], '[') != -1 {
+			return "", nil, false
+		}
+		segments = append(segments, remaining[1:end])
+		remaining = remaining[end+1:]
+	}
+	return key[:start], segments, true
+}
+
+// paramKey is the parsed key of a filter parameter in the format
+// filter[param][name][operation][alias] with an optional "[]" suffix.
+type paramKey struct {
+	name      string
+	operation string
+	alias     string
+	// list is set if the key ends with "[]".
+	list bool
+}
+
+// parseParamKey parses the segments following filter[param]. Omitted
+// operations and aliases are set to their defaults. Returns false if no
+// name has been passed.
+func parseParamKey(segments []string) (*paramKey, bool) {
+	key := &paramKey{operation: defaultOperation}
+	if len(segments) > 1 && len(segments[len(segments)-1]) == 0 {
+		key.list = true
+		segments = segments[:len(segments)-1]
+	}
+	if len(segments) == 0 || len(segments[0]) == 0 {
+		return nil, false
+	}
+	key.name = segments[0]
+	key.alias = key.name
+	if len(segments) > 1 && len(segments[1]) > 0 {
+		key.operation = segments[1]
+	}
+	if len(segments) > 2 && len(segments[2]) > 0 {
+		key.alias = segments[2]
+	}
+	return key, true
+}
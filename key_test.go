@@ -0,0 +1,89 @@
+package filterparams
+
+import (
+	. "gopkg.in/check.v1"
+
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+var _ = Suite(&KeyTest{})
+
+type KeyTest struct{}
+
+func (t *KeyTest) TestSplitKey(c *C) {
+	prefix, segments, ok := splitKey("filter[param][address.city][eq][city]")
+	c.Assert(ok, Equals, true)
+	c.Assert(prefix, Equals, "filter")
+	c.Assert(segments, DeepEquals, []string{"param", "address.city", "eq", "city"})
+
+	_, segments, ok = splitKey("filter[param][id][in][]")
+	c.Assert(ok, Equals, true)
+	c.Assert(segments, DeepEquals, []string{"param", "id", "in", ""})
+}
+
+func (t *KeyTest) TestSplitKeyInvalid(c *C) {
+	for _, key := range []string{"filter", "[param]", "filter[param", "filter[param]x", "filter[pa[ram]"} {
+		_, _, ok := splitKey(key)
+		c.Assert(ok, Equals, false, Commentf("%s", key))
+	}
+}
+
+func (t *KeyTest) TestParseParamKey(c *C) {
+	key, ok := parseParamKey([]string{"name"})
+	c.Assert(ok, Equals, true)
+	c.Assert(*key, Equals, paramKey{name: "name", operation: "eq", alias: "name"})
+
+	key, ok = parseParamKey([]string{"id", "in", "ids", ""})
+	c.Assert(ok, Equals, true)
+	c.Assert(*key, Equals, paramKey{name: "id", operation: "in", alias: "ids", list: true})
+
+	key, ok = parseParamKey([]string{"id", ""})
+	c.Assert(ok, Equals, true)
+	c.Assert(*key, Equals, paramKey{name: "id", operation: "eq", alias: "id", list: true})
+
+	_, ok = parseParamKey([]string{""})
+	c.Assert(ok, Equals, false)
+}
+
+func (t *KeyTest) TestParseOrder(c *C) {
+	name, direction, nulls, err := parseOrder("desc(address.city)")
+	c.Assert(err, IsNil)
+	c.Assert(name, Equals, "address.city")
+	c.Assert(direction, Equals, "desc")
+	c.Assert(nulls, Equals, definition.NullsDefault)
+	name, direction, _, err = parseOrder(" größe ")
+	c.Assert(err, IsNil)
+	c.Assert(name, Equals, "größe")
+	c.Assert(direction, Equals, "asc")
+}
+
+func (t *KeyTest) TestParseOrderNulls(c *C) {
+	name, direction, nulls, err := parseOrder("desc(name, nullslast)")
+	c.Assert(err, IsNil)
+	c.Assert(name, Equals, "name")
+	c.Assert(direction, Equals, "desc")
+	c.Assert(nulls, Equals, definition.NullsLast)
+	_, direction, nulls, err = parseOrder("asc(name,NullsFirst)")
+	c.Assert(err, IsNil)
+	c.Assert(direction, Equals, "asc")
+	c.Assert(nulls, Equals, definition.NullsFirst)
+
+	for _, order := range []string{"asc(name,nulls)", "desc(name,nullslast,x)"} {
+		_, _, _, err = parseOrder(order)
+		c.Assert(err, FitsTypeOf, &InvalidOrderError{}, Commentf("%s", order))
+	}
+}
+
+func (t *KeyTest) TestParseSort(c *C) {
+	orders, errs := parseSort([]string{"-created, name", "author.name"}, nil)
+	c.Assert(errs, HasLen, 0)
+	c.Assert(orders, DeepEquals, []*definition.Order{
+		definition.NewOrderDesc("created"),
+		definition.NewOrderAsc("name"),
+		definition.NewOrderAsc("author.name"),
+	})
+
+	_, errs = parseSort([]string{"-na$me"}, nil)
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0].(*InvalidNameError).Name, Equals, "na$me")
+}
@@ -0,0 +1,95 @@
+package filterparams
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cbrand/go-filterparams/binding"
+)
+
+// Limit identifies a complexity limit of a query.
+type Limit string
+
+const (
+	// LimitParameters limits the amount of filter[param] keys.
+	LimitParameters Limit = "parameters"
+	// LimitBindingDepth limits the nesting of brackets and negations in
+	// the filter[binding].
+	LimitBindingDepth Limit = "binding depth"
+	// LimitBindingLength limits the length of the filter[binding].
+	LimitBindingLength Limit = "binding length"
+	// LimitOrders limits the amount of filter[order] entries.
+	LimitOrders Limit = "orders"
+	// LimitListSize limits the amount of values of list parameters like
+	// the ones of the "in" filter.
+	LimitListSize Limit = "list size"
+)
+
+// Limits configures the maximum complexity of a query. A value of 0
+// disables the respective limit.
+type Limits struct {
+	MaxParameters    int
+	MaxBindingDepth  int
+	MaxBindingLength int
+	MaxOrders        int
+	MaxListSize      int
+}
+
+// LimitExceededError indicates that a query exceeds one of the configured
+// complexity limits.
+type LimitExceededError struct {
+	errorKey
+	// Limit is the limit which has been exceeded.
+	Limit Limit
+	// Max is the configured maximum.
+	Max int
+	// Actual is the value of the query.
+	Actual int
+}
+
+// Error returns the formatted error message.
+func (l *LimitExceededError) Error() string {
+	return fmt.Sprintf("The number of %s (%d) exceeds the maximum of %d", l.Limit, l.Actual, l.Max)
+}
+
+// NewLimitExceededError generates the error for the passed limit.
+func NewLimitExceededError(limit Limit, max, actual int) *LimitExceededError {
+	return &LimitExceededError{
+		Limit:  limit,
+		Max:    max,
+		Actual: actual,
+	}
+}
+
+// checkLimit returns a LimitExceededError if the actual value exceeds the
+// maximum. A maximum of 0 is unlimited.
+func checkLimit(limit Limit, max, actual int) error {
+	if max > 0 && actual > max {
+		return NewLimitExceededError(limit, max, actual)
+	}
+	return nil
+}
+
+// checkParameters validates the amount of filter parameter keys.
+func (l Limits) checkParameters(keys []string) error {
+	count := 0
+	for _, key := range keys {
+		if strings.HasPrefix(key, "filter[param]") {
+			count++
+		}
+	}
+	return checkLimit(LimitParameters, l.MaxParameters, count)
+}
+
+// checkBinding validates the length and depth of a binding string. The
+// length is checked first so the depth is only calculated for bindings
+// with an acceptable length.
+func (l Limits) checkBinding(queryBinding string) error {
+	if err := checkLimit(LimitBindingLength, l.MaxBindingLength, len(queryBinding)); err != nil {
+		return err
+	}
+	if l.MaxBindingDepth > 0 {
+		return checkLimit(LimitBindingDepth, l.MaxBindingDepth, binding.Depth(queryBinding))
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package filterparams
+
+import (
+	"reflect"
+
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+const defaultListSeparator = ','
+const defaultListEscape = '\\'
+
+// splitList splits the value by the separator. Separators and escape
+// characters which are preceded by the escape character are taken
+// literally, as is a trailing escape character. An empty value results in
+// an empty list.
+func splitList(value string, separator, escape rune) []string {
+	items := []string{}
+	if len(value) == 0 {
+		return items
+	}
+	current := []rune{}
+	escaped := false
+	for _, char := range value {
+		switch {
+		case escaped:
+			current = append(current, char)
+			escaped = false
+		case char == escape:
+			escaped = true
+		case char == separator:
+			items = append(items, string(current))
+			current = []rune{}
+		default:
+			current = append(current, char)
+		}
+	}
+	if escaped {
+		current = append(current, escape)
+	}
+	return append(items, string(current))
+}
+
+// countList returns the amount of items splitList returns for the value
+// without splitting it.
+func countList(value string, separator, escape rune) int {
+	if len(value) == 0 {
+		return 0
+	}
+	count := 1
+	escaped := false
+	for _, char := range value {
+		switch {
+		case escaped:
+			escaped = false
+		case char == escape:
+			escaped = true
+		case char == separator:
+			count++
+		}
+	}
+	return count
+}
+
+// convertValue converts a single value with the field type. If no field
+// type is given the value is returned unchanged.
+func convertValue(paramName, value string, fieldType definition.FieldType) (interface{}, error) {
+	if fieldType == nil {
+		return value, nil
+	}
+	converted, err := fieldType.Convert(value)
+	if err != nil {
+		return nil, NewValueConversionError(paramName, value, fieldType.Name(), err)
+	}
+	return converted, nil
+}
+
+// convertList converts all items with the field type and returns them as a
+// slice of the converted type. Untyped items are returned as a []string and
+// items of mixed or unknown types as a []interface{}.
+func convertList(paramName string, items []string, fieldType definition.FieldType) (interface{}, error) {
+	if fieldType == nil {
+		return items, nil
+	}
+	converted := make([]interface{}, len(items))
+	for index, item := range items {
+		value, err := convertValue(paramName, item, fieldType)
+		if err != nil {
+			return nil, err
+		}
+		converted[index] = value
+	}
+	if len(converted) == 0 || converted[0] == nil {
+		return converted, nil
+	}
+	itemType := reflect.TypeOf(converted[0])
+	list := reflect.MakeSlice(reflect.SliceOf(itemType), 0, len(converted))
+	for _, value := range converted {
+		if reflect.TypeOf(value) != itemType {
+			return converted, nil
+		}
+		list = reflect.Append(list, reflect.ValueOf(value))
+	}
+	return list.Interface(), nil
+}
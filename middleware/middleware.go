@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/cbrand/go-filterparams"
+	"github.com/cbrand/go-filterparams/jsonapi"
+)
+
+// contextKey is the type of the key the query data is stored under.
+type contextKey struct{}
+
+// ErrorHandlerFunc writes the response for requests whose query could
+// not be parsed.
+type ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+
+// Middleware parses the query parameters of every request and stores the
+// result in the request context.
+type Middleware struct {
+	query        *filterparams.Query
+	errorHandler ErrorHandlerFunc
+}
+
+// SetErrorHandler sets the handler which is called if the query can not
+// be parsed. Defaults to writing a JSON:API error document.
+func (m *Middleware) SetErrorHandler(errorHandler ErrorHandlerFunc) *Middleware {
+	m.errorHandler = errorHandler
+	return m
+}
+
+// Handler wraps the handler. The next handler is only called if the query
+// could be parsed.
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		values := r.URL.Query()
+		queryData, err := m.query.Parse(&values)
+		if err != nil {
+			m.errorHandler(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), queryData)))
+	})
+}
+
+// writeJSONAPIError is the default error handler.
+func writeJSONAPIError(w http.ResponseWriter, r *http.Request, err error) {
+	jsonapi.WriteError(w, err)
+}
+
+// New returns a middleware which parses the requests with the query.
+func New(query *filterparams.Query) *Middleware {
+	return &Middleware{
+		query:        query,
+		errorHandler: writeJSONAPIError,
+	}
+}
+
+// NewContext returns a copy of the context which carries the query data.
+func NewContext(ctx context.Context, queryData *filterparams.QueryData) context.Context {
+	return context.WithValue(ctx, contextKey{}, queryData)
+}
+
+// FromContext returns the query data stored in the context. Returns false if
+// the context carries no query data.
+func FromContext(ctx context.Context) (*filterparams.QueryData, bool) {
+	queryData, ok := ctx.Value(contextKey{}).(*filterparams.QueryData)
+	return queryData, ok
+}
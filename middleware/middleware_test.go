@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/cbrand/go-filterparams"
+	"github.com/cbrand/go-filterparams/definition"
+	"github.com/cbrand/go-filterparams/jsonapi"
+)
+
+var _ = Suite(&MiddlewareTest{})
+
+type MiddlewareTest struct {
+	middleware *Middleware
+	queryData  *filterparams.QueryData
+	called     bool
+}
+
+func (t *MiddlewareTest) SetUpTest(c *C) {
+	builder := filterparams.NewBuilder()
+	builder.EnableFilter(definition.FilterEq)
+	t.middleware = New(builder.CreateQuery())
+	t.queryData = nil
+	t.called = false
+}
+
+func (t *MiddlewareTest) serve(target string) *httptest.ResponseRecorder {
+	handler := t.middleware.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.called = true
+		t.queryData, _ = FromContext(r.Context())
+	}))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", target, nil))
+	return recorder
+}
+
+func (t *MiddlewareTest) TestParse(c *C) {
+	recorder := t.serve("/users?filter[param][name]=doe&filter[order]=desc(name)")
+	c.Assert(recorder.Code, Equals, http.StatusOK)
+	c.Assert(t.called, Equals, true)
+	c.Assert(t.queryData, NotNil)
+	c.Assert(t.queryData.GetFilter().(*definition.Parameter).Value, Equals, "doe")
+	c.Assert(t.queryData.GetOrders()[0].GetOrderBy(), Equals, "name")
+}
+
+func (t *MiddlewareTest) TestDefaultErrorHandler(c *C) {
+	recorder := t.serve("/users?filter[param][name][like]=doe")
+	c.Assert(t.called, Equals, false)
+	c.Assert(recorder.Code, Equals, http.StatusBadRequest)
+	c.Assert(recorder.Header().Get("Content-Type"), Equals, jsonapi.ContentType)
+}
+
+func (t *MiddlewareTest) TestCustomErrorHandler(c *C) {
+	var handledErr error
+	t.middleware.SetErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		handledErr = err
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	})
+	recorder := t.serve("/users?filter[param][name][like]=doe")
+	c.Assert(t.called, Equals, false)
+	c.Assert(recorder.Code, Equals, http.StatusUnprocessableEntity)
+	_, ok := handledErr.(*filterparams.UnsupportedOperationError)
+	c.Assert(ok, Equals, true)
+}
+
+func (t *MiddlewareTest) TestFromContextEmpty(c *C) {
+	queryData, ok := FromContext(context.Background())
+	c.Assert(ok, Equals, false)
+	c.Assert(queryData, IsNil)
+}
@@ -0,0 +1,40 @@
+package optimize
+
+import (
+	"testing"
+
+	"github.com/cbrand/go-filterparams/definition"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+func param(name string, filter *definition.Filter, value interface{}) *definition.Parameter {
+	parameter := definition.NewParameter(name)
+	parameter.Name = name
+	parameter.Filter = filter
+	parameter.Value = value
+	return parameter
+}
+
+func eq(name string, value interface{}) *definition.Parameter {
+	return param(name, definition.FilterEq, value)
+}
+
+func and(left, right interface{}) *definition.And {
+	node := definition.NewAnd()
+	node.Left, node.Right = left, right
+	return node
+}
+
+func or(left, right interface{}) *definition.Or {
+	node := definition.NewOr()
+	node.Left, node.Right = left, right
+	return node
+}
+
+func not(negated interface{}) *definition.Negate {
+	return definition.NewNegate(negated)
+}
@@ -0,0 +1,253 @@
+package optimize
+
+import (
+	"math/big"
+	"reflect"
+	"time"
+
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+// IsContradiction returns if the tree can never match, e.g. "x eq 1 AND x eq 2"
+// or "x gt 5 AND x lt 3". The tree is converted into disjunctive normal form
+// and every clause is checked. An error is returned if more than maxClauses
+// clauses would be required. A maxClauses of 0 disables the limit.
+//
+// Parameters of dotted paths are only compared if every hop is a declared
+// to-one relationship. A to-many path like "roles.name" may match a
+// different related item for every parameter.
+//
+// Values are only compared if they have the same type. Range checks are only
+// done for numbers, decimals and times, thus untyped string values are only
+// checked for equality.
+func IsContradiction(node definition.Node, maxClauses int) (bool, error) {
+	clauses, err := dnfClauses(node, maxClauses)
+	if err != nil {
+		return false, err
+	}
+	for _, clause := range clauses {
+		if !clauseContradicts(clause) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// fieldConstraints collects the literals of one field in a clause.
+type fieldConstraints struct {
+	equals []interface{}
+	lower  []bound
+	upper  []bound
+	lists  [][]interface{}
+}
+
+// bound is a lower or upper limit of a field.
+type bound struct {
+	value     interface{}
+	inclusive bool
+}
+
+// clauseContradicts returns if the literals which are combined with AND
+// can never match together.
+func clauseContradicts(literals []definition.Node) bool {
+	positive := map[string]bool{}
+	negative := map[string]bool{}
+	constraints := map[string]*fieldConstraints{}
+	for _, literal := range literals {
+		if negate, ok := literal.(*definition.Negate); ok {
+			negated := negate.Children()
+			if len(negated) == 1 {
+				negative[definition.Describe(negated[0])] = true
+			}
+			continue
+		}
+		parameter, ok := literal.(*definition.Parameter)
+		if !ok || parameter.Filter == nil {
+			continue
+		}
+		positive[definition.Describe(parameter)] = true
+		if !singleValued(parameter) {
+			continue
+		}
+		fieldConstraint, ok := constraints[parameter.Name]
+		if !ok {
+			fieldConstraint = &fieldConstraints{}
+			constraints[parameter.Name] = fieldConstraint
+		}
+		switch parameter.Filter.Identification {
+		case definition.FilterEq.Identification:
+			fieldConstraint.equals = append(fieldConstraint.equals, parameter.Value)
+		case definition.FilterGt.Identification:
+			fieldConstraint.lower = append(fieldConstraint.lower, bound{parameter.Value, false})
+		case definition.FilterGte.Identification:
+			fieldConstraint.lower = append(fieldConstraint.lower, bound{parameter.Value, true})
+		case definition.FilterLt.Identification:
+			fieldConstraint.upper = append(fieldConstraint.upper, bound{parameter.Value, false})
+		case definition.FilterLte.Identification:
+			fieldConstraint.upper = append(fieldConstraint.upper, bound{parameter.Value, true})
+		case definition.FilterIn.Identification:
+			fieldConstraint.lists = append(fieldConstraint.lists, listValues(parameter.Value))
+		}
+	}
+	for description := range positive {
+		if negative[description] {
+			return true
+		}
+	}
+	for _, fieldConstraint := range constraints {
+		if fieldConstraint.contradicts() {
+			return true
+		}
+	}
+	return false
+}
+
+// singleValued returns if the parameter refers to at most one value. This
+// is the case for plain names and paths through to-one relationships only.
+func singleValued(parameter *definition.Parameter) bool {
+	path := parameter.GetPath()
+	if len(path) == 1 {
+		return true
+	}
+	if len(parameter.Relationships) != len(path)-1 {
+		return false
+	}
+	for _, relationship := range parameter.Relationships {
+		if !relationship.ToOne {
+			return false
+		}
+	}
+	return true
+}
+
+// contradicts returns if no value can fulfill all constraints.
+func (f *fieldConstraints) contradicts() bool {
+	for index, value := range f.equals {
+		for _, other := range f.equals[index+1:] {
+			if sameType(value, other) && !equal(value, other) {
+				return true
+			}
+		}
+		for _, lower := range f.lower {
+			if result, ok := compare(value, lower.value); ok && (result < 0 || (result == 0 && !lower.inclusive)) {
+				return true
+			}
+		}
+		for _, upper := range f.upper {
+			if result, ok := compare(value, upper.value); ok && (result > 0 || (result == 0 && !upper.inclusive)) {
+				return true
+			}
+		}
+		for _, list := range f.lists {
+			if !listContains(list, value) {
+				return true
+			}
+		}
+	}
+	for _, list := range f.lists {
+		if len(list) == 0 {
+			return true
+		}
+	}
+	for _, lower := range f.lower {
+		for _, upper := range f.upper {
+			result, ok := compare(lower.value, upper.value)
+			if ok && (result > 0 || (result == 0 && !(lower.inclusive && upper.inclusive))) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// equal returns if both values of the same type are equal. Numbers,
+// decimals and times are compared by their value, e.g. the decimals 1.0
+// and 1 are equal. Other values are compared by their description.
+func equal(left, right interface{}) bool {
+	if result, ok := compare(left, right); ok {
+		return result == 0
+	}
+	return definition.Describe(literal(left)) == definition.Describe(literal(right))
+}
+
+// literal wraps the value in a parameter to compare it by its description.
+func literal(value interface{}) *definition.Parameter {
+	parameter := definition.NewParameter("")
+	parameter.Value = value
+	return parameter
+}
+
+// listContains returns if the list contains the value. Lists with values of
+// a different type are assumed to contain the value.
+func listContains(list []interface{}, value interface{}) bool {
+	for _, entry := range list {
+		if !sameType(entry, value) || equal(entry, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// listValues returns the entries of a list value. Non slice values are
+// handled as a list with one entry.
+func listValues(value interface{}) []interface{} {
+	reflected := reflect.ValueOf(value)
+	if reflected.Kind() != reflect.Slice && reflected.Kind() != reflect.Array {
+		return []interface{}{value}
+	}
+	values := make([]interface{}, reflected.Len())
+	for index := range values {
+		values[index] = reflected.Index(index).Interface()
+	}
+	return values
+}
+
+// sameType returns if both values have the same type.
+func sameType(left, right interface{}) bool {
+	return reflect.TypeOf(left) == reflect.TypeOf(right)
+}
+
+// compare compares numbers, decimals and times of the same type. Returns
+// false if the values can't be compared.
+func compare(left, right interface{}) (int, bool) {
+	if !sameType(left, right) {
+		return 0, false
+	}
+	if leftTime, ok := left.(time.Time); ok {
+		rightTime := right.(time.Time)
+		switch {
+		case leftTime.Before(rightTime):
+			return -1, true
+		case leftTime.After(rightTime):
+			return 1, true
+		}
+		return 0, true
+	}
+	leftNumber, ok := toRat(left)
+	if !ok {
+		return 0, false
+	}
+	rightNumber, ok := toRat(right)
+	if !ok {
+		return 0, false
+	}
+	return leftNumber.Cmp(rightNumber), true
+}
+
+// toRat converts numbers and decimals to an exact rational number.
+func toRat(value interface{}) (*big.Rat, bool) {
+	if decimal, ok := value.(definition.Decimal); ok {
+		return new(big.Rat).SetString(string(decimal))
+	}
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(reflected.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(reflected.Uint())), true
+	case reflect.Float32, reflect.Float64:
+		rat := new(big.Rat).SetFloat64(reflected.Float())
+		return rat, rat != nil
+	}
+	return nil, false
+}
@@ -0,0 +1,97 @@
+package optimize
+
+import (
+	"time"
+
+	"github.com/cbrand/go-filterparams/definition"
+	. "gopkg.in/check.v1"
+)
+
+var _ = Suite(&ContradictionTest{})
+
+type ContradictionTest struct{}
+
+func (t *ContradictionTest) contradiction(c *C, node definition.Node) bool {
+	result, err := IsContradiction(node, 0)
+	c.Assert(err, IsNil)
+	return result
+}
+
+func (t *ContradictionTest) TestDifferentEquals(c *C) {
+	c.Assert(t.contradiction(c, and(eq("x", int64(1)), eq("x", int64(2)))), Equals, true)
+	c.Assert(t.contradiction(c, and(eq("x", int64(1)), eq("x", int64(1)))), Equals, false)
+	c.Assert(t.contradiction(c, and(eq("x", int64(1)), eq("y", int64(2)))), Equals, false)
+	c.Assert(t.contradiction(c, and(eq("x", int64(1)), eq("x", "2"))), Equals, false)
+}
+
+func (t *ContradictionTest) TestDecimalEquals(c *C) {
+	one, onePointZero := definition.Decimal("1"), definition.Decimal("1.0")
+	c.Assert(t.contradiction(c, and(eq("x", onePointZero), eq("x", one))), Equals, false)
+	c.Assert(t.contradiction(c, and(eq("x", one), eq("x", definition.Decimal("1.5")))), Equals, true)
+	in := param("x", definition.FilterIn, []definition.Decimal{onePointZero})
+	c.Assert(t.contradiction(c, and(eq("x", one), in)), Equals, false)
+}
+
+func (t *ContradictionTest) TestNegatedLiteral(c *C) {
+	c.Assert(t.contradiction(c, and(eq("x", "a"), not(eq("x", "a")))), Equals, true)
+	c.Assert(t.contradiction(c, not(or(eq("x", "a"), not(eq("x", "a"))))), Equals, true)
+	c.Assert(t.contradiction(c, and(eq("x", "a"), not(eq("x", "b")))), Equals, false)
+}
+
+func (t *ContradictionTest) TestRanges(c *C) {
+	gt := func(value interface{}) definition.Node { return param("x", definition.FilterGt, value) }
+	gte := func(value interface{}) definition.Node { return param("x", definition.FilterGte, value) }
+	lt := func(value interface{}) definition.Node { return param("x", definition.FilterLt, value) }
+	lte := func(value interface{}) definition.Node { return param("x", definition.FilterLte, value) }
+
+	c.Assert(t.contradiction(c, and(gt(int64(5)), lt(int64(3)))), Equals, true)
+	c.Assert(t.contradiction(c, and(gt(int64(3)), lt(int64(3)))), Equals, true)
+	c.Assert(t.contradiction(c, and(gte(int64(3)), lte(int64(3)))), Equals, false)
+	c.Assert(t.contradiction(c, and(gte(int64(3)), lt(int64(5)))), Equals, false)
+	c.Assert(t.contradiction(c, and(eq("x", 2.5), gte(3.0))), Equals, true)
+	c.Assert(t.contradiction(c, and(eq("x", 3.0), gte(3.0))), Equals, false)
+	c.Assert(t.contradiction(c, and(eq("x", definition.Decimal("10.50")), lt(definition.Decimal("10.5")))), Equals, true)
+	c.Assert(t.contradiction(c, and(gt("b"), lt("a"))), Equals, false)
+}
+
+func (t *ContradictionTest) TestTimeRanges(c *C) {
+	early := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+	c.Assert(t.contradiction(c, and(param("x", definition.FilterGt, late), param("x", definition.FilterLt, early))), Equals, true)
+	c.Assert(t.contradiction(c, and(param("x", definition.FilterGt, early), param("x", definition.FilterLt, late))), Equals, false)
+}
+
+func (t *ContradictionTest) TestInList(c *C) {
+	in := param("x", definition.FilterIn, []string{"a", "b"})
+	c.Assert(t.contradiction(c, and(eq("x", "c"), in)), Equals, true)
+	c.Assert(t.contradiction(c, and(eq("x", "a"), in)), Equals, false)
+	c.Assert(t.contradiction(c, param("x", definition.FilterIn, []string{})), Equals, true)
+}
+
+func (t *ContradictionTest) TestRelationshipPaths(c *C) {
+	path := func(value string, relationships ...*definition.Relationship) *definition.Parameter {
+		parameter := eq("roles.name", value)
+		parameter.Path = definition.SplitPath(parameter.Name)
+		parameter.Relationships = relationships
+		return parameter
+	}
+	roles := definition.NewRelationship("roles", "roles", "id", "user_id").EnableField("name", "")
+	c.Assert(t.contradiction(c, and(path("editor", roles), path("owner", roles))), Equals, false)
+	c.Assert(t.contradiction(c, and(path("editor"), path("owner"))), Equals, false)
+	c.Assert(t.contradiction(c, and(path("editor", roles), not(path("editor", roles)))), Equals, true)
+
+	role := definition.NewRelationship("roles", "roles", "role_id", "id").SetToOne(true)
+	c.Assert(t.contradiction(c, and(path("editor", role), path("owner", role))), Equals, true)
+}
+
+func (t *ContradictionTest) TestAllClauses(c *C) {
+	contradiction := and(eq("x", int64(1)), eq("x", int64(2)))
+	c.Assert(t.contradiction(c, or(contradiction, eq("y", "1"))), Equals, false)
+	c.Assert(t.contradiction(c, or(contradiction, and(eq("y", "1"), not(eq("y", "1"))))), Equals, true)
+}
+
+func (t *ContradictionTest) TestComplexityLimit(c *C) {
+	node := and(or(eq("a", "1"), eq("b", "2")), or(eq("c", "3"), eq("d", "4")))
+	_, err := IsContradiction(node, 2)
+	c.Assert(err, FitsTypeOf, &ComplexityError{})
+}
@@ -0,0 +1,18 @@
+package optimize
+
+import "github.com/cbrand/go-filterparams/definition"
+
+// RemoveDuplicates removes operands of nested And and Or statements which
+// are equal to a previous operand, e.g. "a&b&a" becomes "a&b". Parameters
+// are compared by their name, filter and value, their alias is ignored.
+func RemoveDuplicates(node definition.Node) (definition.Node, error) {
+	return definition.Rewrite(node, func(node definition.Node) (definition.Node, error) {
+		switch node.(type) {
+		case *definition.And:
+			return definition.JoinLeftDeep(unique(definition.Flatten(node)), newAnd), nil
+		case *definition.Or:
+			return definition.JoinLeftDeep(unique(definition.Flatten(node)), newOr), nil
+		}
+		return node, nil
+	})
+}
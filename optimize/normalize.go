@@ -0,0 +1,183 @@
+package optimize
+
+import (
+	"fmt"
+
+	"github.com/cbrand/go-filterparams/definition"
+)
+
+// ComplexityError is returned if a normal form would exceed the maximum
+// amount of clauses.
+type ComplexityError struct {
+	MaxClauses int
+	Clauses    int
+}
+
+// Error returns the formatted error message.
+func (c *ComplexityError) Error() string {
+	return fmt.Sprintf("The normal form requires %d clauses, only %d are allowed", c.Clauses, c.MaxClauses)
+}
+
+// PushNegations moves all negations down to the parameters by applying
+// De Morgan's laws and removes double negations. The result is in
+// negation normal form.
+func PushNegations(node definition.Node) (definition.Node, error) {
+	return pushNegations(node, false)
+}
+
+// pushNegations returns the negation normal form of the node. If negate is
+// set the negation of the node is returned.
+func pushNegations(node definition.Node, negate bool) (definition.Node, error) {
+	switch statement := node.(type) {
+	case *definition.Parameter:
+		if negate {
+			return definition.NewNegate(statement), nil
+		}
+		return statement, nil
+	case *definition.Negate:
+		children := statement.Children()
+		if len(children) != 1 {
+			return nil, fmt.Errorf("Unexpected node %T in filter", statement.Negated)
+		}
+		return pushNegations(children[0], !negate)
+	case *definition.And, *definition.Or:
+		children := statement.Children()
+		if len(children) != 2 {
+			return nil, fmt.Errorf("Unexpected nodes in %T", statement)
+		}
+		left, err := pushNegations(children[0], negate)
+		if err != nil {
+			return nil, err
+		}
+		right, err := pushNegations(children[1], negate)
+		if err != nil {
+			return nil, err
+		}
+		_, isAnd := statement.(*definition.And)
+		if isAnd != negate {
+			return newAnd(left, right), nil
+		}
+		return newOr(left, right), nil
+	}
+	return nil, fmt.Errorf("Unexpected node %T in filter", node)
+}
+
+// ToDNF converts the tree into disjunctive normal form: an OR of ANDs of
+// parameters or negated parameters. An error is returned if more than
+// maxClauses clauses would be created. A maxClauses of 0 disables the limit.
+func ToDNF(node definition.Node, maxClauses int) (definition.Node, error) {
+	clauses, err := dnfClauses(node, maxClauses)
+	if err != nil {
+		return nil, err
+	}
+	return joinClauses(clauses, newOr, newAnd), nil
+}
+
+// ToCNF converts the tree into conjunctive normal form: an AND of ORs of
+// parameters or negated parameters. An error is returned if more than
+// maxClauses clauses would be created. A maxClauses of 0 disables the limit.
+func ToCNF(node definition.Node, maxClauses int) (definition.Node, error) {
+	normalized, err := PushNegations(node)
+	if err != nil {
+		return nil, err
+	}
+	clauses, err := normalFormClauses(normalized, maxClauses, false)
+	if err != nil {
+		return nil, err
+	}
+	return joinClauses(clauses, newAnd, newOr), nil
+}
+
+// dnfClauses returns the clauses of the disjunctive normal form. Each
+// clause is a list of literals which are combined with AND.
+func dnfClauses(node definition.Node, maxClauses int) ([][]definition.Node, error) {
+	normalized, err := PushNegations(node)
+	if err != nil {
+		return nil, err
+	}
+	return normalFormClauses(normalized, maxClauses, true)
+}
+
+// normalFormClauses distributes the tree which must be in negation normal
+// form. For the disjunctive normal form the OR statements are the outer
+// ones, for the conjunctive normal form the AND statements.
+func normalFormClauses(node definition.Node, maxClauses int, disjunctive bool) ([][]definition.Node, error) {
+	switch statement := node.(type) {
+	case *definition.Parameter, *definition.Negate:
+		return [][]definition.Node{{statement}}, nil
+	case *definition.And, *definition.Or:
+		children := statement.Children()
+		left, err := normalFormClauses(children[0], maxClauses, disjunctive)
+		if err != nil {
+			return nil, err
+		}
+		right, err := normalFormClauses(children[1], maxClauses, disjunctive)
+		if err != nil {
+			return nil, err
+		}
+		_, isOr := statement.(*definition.Or)
+		if isOr == disjunctive {
+			return checkClauses(append(left, right...), maxClauses)
+		}
+		if maxClauses > 0 && len(left)*len(right) > maxClauses {
+			return nil, &ComplexityError{MaxClauses: maxClauses, Clauses: len(left) * len(right)}
+		}
+		product := make([][]definition.Node, 0, len(left)*len(right))
+		for _, leftClause := range left {
+			for _, rightClause := range right {
+				clause := make([]definition.Node, 0, len(leftClause)+len(rightClause))
+				clause = append(clause, leftClause...)
+				product = append(product, append(clause, rightClause...))
+			}
+		}
+		return product, nil
+	}
+	return nil, fmt.Errorf("Unexpected node %T in filter", node)
+}
+
+// checkClauses returns an error if there are more clauses than allowed.
+func checkClauses(clauses [][]definition.Node, maxClauses int) ([][]definition.Node, error) {
+	if maxClauses > 0 && len(clauses) > maxClauses {
+		return nil, &ComplexityError{MaxClauses: maxClauses, Clauses: len(clauses)}
+	}
+	return clauses, nil
+}
+
+// joinClauses combines the literals of every clause with inner and the
+// clauses with outer. Duplicated literals and clauses are removed.
+func joinClauses(clauses [][]definition.Node, outer, inner func(left, right definition.Node) definition.Node) definition.Node {
+	joined := make([]definition.Node, 0, len(clauses))
+	for _, clause := range clauses {
+		joined = append(joined, definition.JoinLeftDeep(unique(clause), inner))
+	}
+	return definition.JoinLeftDeep(unique(joined), outer)
+}
+
+// unique removes nodes with the same description keeping the first one.
+func unique(nodes []definition.Node) []definition.Node {
+	seen := map[string]bool{}
+	result := make([]definition.Node, 0, len(nodes))
+	for _, node := range nodes {
+		description := definition.Describe(node)
+		if seen[description] {
+			continue
+		}
+		seen[description] = true
+		result = append(result, node)
+	}
+	return result
+}
+
+// newAnd returns an And statement of both nodes.
+func newAnd(left, right definition.Node) definition.Node {
+	and := definition.NewAnd()
+	and.Left, and.Right = left, right
+	return and
+}
+
+// newOr returns an Or statement of both nodes.
+func newOr(left, right definition.Node) definition.Node {
+	or := definition.NewOr()
+	or.Left, or.Right = left, right
+	return or
+}
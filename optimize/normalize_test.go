@@ -0,0 +1,70 @@
+package optimize
+
+import (
+	"github.com/cbrand/go-filterparams/definition"
+	. "gopkg.in/check.v1"
+)
+
+var _ = Suite(&NormalizeTest{})
+
+type NormalizeTest struct{}
+
+func (t *NormalizeTest) TestPushNegations(c *C) {
+	node, err := PushNegations(not(and(eq("a", "1"), or(eq("b", "2"), not(eq("c", "3"))))))
+	c.Assert(err, IsNil)
+	expected := or(not(eq("a", "1")), and(not(eq("b", "2")), eq("c", "3")))
+	c.Assert(definition.Describe(node), Equals, definition.Describe(expected))
+}
+
+func (t *NormalizeTest) TestPushNegationsDoubleNegation(c *C) {
+	node, err := PushNegations(not(not(eq("a", "1"))))
+	c.Assert(err, IsNil)
+	c.Assert(node, DeepEquals, eq("a", "1"))
+}
+
+func (t *NormalizeTest) TestToDNF(c *C) {
+	node, err := ToDNF(and(or(eq("a", "1"), eq("b", "2")), eq("c", "3")), 0)
+	c.Assert(err, IsNil)
+	expected := or(and(eq("a", "1"), eq("c", "3")), and(eq("b", "2"), eq("c", "3")))
+	c.Assert(definition.Describe(node), Equals, definition.Describe(expected))
+}
+
+func (t *NormalizeTest) TestToCNF(c *C) {
+	node, err := ToCNF(or(and(eq("a", "1"), eq("b", "2")), eq("c", "3")), 0)
+	c.Assert(err, IsNil)
+	expected := and(or(eq("a", "1"), eq("c", "3")), or(eq("b", "2"), eq("c", "3")))
+	c.Assert(definition.Describe(node), Equals, definition.Describe(expected))
+}
+
+func (t *NormalizeTest) TestNormalFormRemovesDuplicates(c *C) {
+	node, err := ToDNF(and(or(eq("a", "1"), eq("a", "1")), eq("a", "1")), 0)
+	c.Assert(err, IsNil)
+	c.Assert(definition.Describe(node), Equals, definition.Describe(eq("a", "1")))
+}
+
+func (t *NormalizeTest) TestComplexityLimit(c *C) {
+	node := and(and(or(eq("a", "1"), eq("b", "2")), or(eq("c", "3"), eq("d", "4"))), or(eq("e", "5"), eq("f", "6")))
+	_, err := ToDNF(node, 4)
+	c.Assert(err, FitsTypeOf, &ComplexityError{})
+	c.Assert(err.(*ComplexityError).Clauses, Equals, 8)
+	c.Assert(err.(*ComplexityError).MaxClauses, Equals, 4)
+
+	_, err = ToDNF(node, 8)
+	c.Assert(err, IsNil)
+	_, err = ToCNF(node, 4)
+	c.Assert(err, IsNil)
+}
+
+func (t *NormalizeTest) TestRemoveDuplicates(c *C) {
+	node, err := RemoveDuplicates(and(and(eq("a", "1"), eq("b", "2")), or(eq("a", "1"), eq("a", "1"))))
+	c.Assert(err, IsNil)
+	c.Assert(definition.Describe(node), Equals, definition.Describe(and(eq("a", "1"), eq("b", "2"))))
+}
+
+func (t *NormalizeTest) TestRemoveDuplicatesIgnoresAlias(c *C) {
+	aliased := eq("a", "1")
+	aliased.Identification = "other"
+	node, err := RemoveDuplicates(or(eq("a", "1"), aliased))
+	c.Assert(err, IsNil)
+	c.Assert(node, DeepEquals, eq("a", "1"))
+}
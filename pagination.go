@@ -0,0 +1,142 @@
+package filterparams
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+)
+
+const (
+	pageNumberKey = "page[number]"
+	pageSizeKey   = "page[size]"
+	pageOffsetKey = "page[offset]"
+	pageLimitKey  = "page[limit]"
+)
+
+// LimitPageSize limits the size of a requested page.
+const LimitPageSize Limit = "page size"
+
+// Page is the window of the result which has been requested.
+type Page struct {
+	// Offset is the amount of entries which are skipped.
+	Offset int
+	// Limit is the maximum amount of returned entries. A value of 0 doesn't
+	// limit the result.
+	Limit int
+}
+
+// Pagination configures the parsing of the page parameters.
+type Pagination struct {
+	// DefaultSize is the page size if none has been requested. A value of
+	// 0 doesn't limit the result.
+	DefaultSize int
+	// MaxSize is the maximum page size which may be requested. A value of
+	// 0 disables the limit.
+	MaxSize int
+}
+
+// InvalidPageError indicates that a page parameter has an invalid value or
+// is combined with a parameter of the other pagination style.
+type InvalidPageError struct {
+	errorKey
+	Value  string
+	Reason string
+}
+
+// Error returns the formatted error message.
+func (i *InvalidPageError) Error() string {
+	return fmt.Sprintf("The page value \"%s\" is invalid: %s", i.Value, i.Reason)
+}
+
+// NewInvalidPageError generates the error for the passed value.
+func NewInvalidPageError(value, reason string) *InvalidPageError {
+	return &InvalidPageError{
+		Value:  value,
+		Reason: reason,
+	}
+}
+
+// parsePage parses page[number] and page[size] or page[offset] and
+// page[limit]. The page size defaults to the configured default size. If
+// a key has been passed multiple times the last value is used.
+func (p *Pagination) parsePage(values *url.Values) (*Page, error) {
+	numbered := hasValue(values, pageNumberKey) || hasValue(values, pageSizeKey)
+	sizeKey, startKey := pageSizeKey, pageNumberKey
+	if hasValue(values, pageOffsetKey) || hasValue(values, pageLimitKey) {
+		if numbered {
+			key := pageOffsetKey
+			if !hasValue(values, key) {
+				key = pageLimitKey
+			}
+			return nil, invalidPage(values, key, "page[number] and page[size] can't be combined with page[offset] and page[limit]")
+		}
+		sizeKey, startKey = pageLimitKey, pageOffsetKey
+	}
+
+	page := &Page{Limit: p.DefaultSize}
+	if hasValue(values, sizeKey) {
+		size, err := parsePageValue(values, sizeKey, 1)
+		if err != nil {
+			return nil, err
+		}
+		if p.MaxSize > 0 && size > p.MaxSize {
+			err := NewLimitExceededError(LimitPageSize, p.MaxSize, size)
+			err.setQueryKey(sizeKey)
+			return nil, err
+		}
+		page.Limit = size
+	}
+	if startKey == pageOffsetKey {
+		offset, err := parsePageValue(values, startKey, 0)
+		if err != nil {
+			return nil, err
+		}
+		page.Offset = offset
+		return page, nil
+	}
+	number, err := parsePageValue(values, startKey, 1)
+	if err != nil {
+		return nil, err
+	}
+	if number > 1 && page.Limit == 0 {
+		return nil, invalidPage(values, startKey, "a page number requires a page size")
+	}
+	if page.Limit > 0 && number-1 > math.MaxInt/page.Limit {
+		return nil, invalidPage(values, startKey, "the page number is too large")
+	}
+	page.Offset = (number - 1) * page.Limit
+	return page, nil
+}
+
+// parsePageValue returns the integer value of the key which has to be at
+// least min. If the key hasn't been passed min is returned.
+func parsePageValue(values *url.Values, key string, min int) (int, error) {
+	if !hasValue(values, key) {
+		return min, nil
+	}
+	value := lastValue(values, key)
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < min {
+		return 0, invalidPage(values, key, fmt.Sprintf("expected an integer of at least %d", min))
+	}
+	return parsed, nil
+}
+
+// invalidPage returns an InvalidPageError for the last value of the key.
+func invalidPage(values *url.Values, key, reason string) *InvalidPageError {
+	err := NewInvalidPageError(lastValue(values, key), reason)
+	err.setQueryKey(key)
+	return err
+}
+
+// hasValue returns if the key has been passed.
+func hasValue(values *url.Values, key string) bool {
+	return len((*values)[key]) > 0
+}
+
+// lastValue returns the last value passed for the key.
+func lastValue(values *url.Values, key string) string {
+	entries := (*values)[key]
+	return entries[len(entries)-1]
+}
@@ -1,48 +1,82 @@
 package filterparams
 
 import (
-	"regexp"
+	"fmt"
+	"reflect"
+	"sort"
 
 	"net/url"
 
+	"github.com/cbrand/go-filterparams/binding"
 	"github.com/cbrand/go-filterparams/definition"
 )
 
 const defaultOperation = "eq"
 
-var paramFilter = regexp.MustCompile("([a-zA-Z1-9_\\-]+)\\[([a-zA-Z1-9_\\-]+)\\](.*)")
-var fieldFilter = regexp.MustCompile("\\[([a-zA-Z1-9_\\-]+)\\](.*)")
-
 // Query can be used to parse query values.
 type Query struct {
 	filters []*definition.Filter
+	fields []*definition.Field
+	fieldFilters map[string][]*definition.Filter
+	fieldTypes map[string]definition.FieldType
+	relationships []*definition.Relationship
 	defaultOperation string
+	listSeparator rune
+	listEscape rune
+	collectErrors bool
+	bindingShape BindingShape
+	limits Limits
+	isNameChar func(rune) bool
+	sortParameter string
+	pagination *Pagination
+	keyset *keyset
+	resourceType string
 }
 
-// parseFilterArguments takes the filter arugments and parses the data.
-func (q *Query) parseFilterArguments(values *url.Values) (*ValueFilterArguments, error) {
+// parseFilterArguments takes the filter arugments and parses the data. Errors are
+// added to the collector. The aliases of parameters which could not be parsed
+// are returned.
+func (q *Query) parseFilterArguments(values *url.Values, errs *errorCollector) (*ValueFilterArguments, map[string]bool) {
 	arguments := NewValueFilterArgument()
+	invalidAliases := map[string]bool{}
+
+	keys := make([]string, 0, len(*values))
+	for param := range *values {
+		keys = append(keys, param)
+	}
+	sort.Strings(keys)
+	if err := q.limits.checkParameters(keys); err != nil {
+		errs.add(err, "filter[param]")
+		return nil, nil
+	}
 
-	for param, valueList := range *values {
-		matches := paramFilter.FindStringSubmatch(param)
-		if len(matches) == 0 {
+	for _, param := range keys {
+		valueList := (*values)[param]
+		category, segments, ok := splitKey(param)
+		if !ok || category != "filter" || len(segments) == 0 {
 			continue
 		}
-		category, innerCategory, remaining := matches[1], matches[2], matches[3]
-		if category != "filter" {
+		innerCategory := segments[0]
+
+		if innerCategory == "param" {
+			key, ok := parseParamKey(segments[1:])
+			if !ok {
+				continue
+			}
+			parameter, err := q.parseFilterParam(key, valueList)
+			if err != nil {
+				invalidAliases[key.alias] = true
+				if errs.add(err, param) {
+					return nil, nil
+				}
+				continue
+			}
+			arguments.SetArgument(parameter.Identification, parameter)
 			continue
 		}
 
 		for _, value := range valueList {
-			matches = fieldFilter.FindStringSubmatch(remaining)
-
-			if innerCategory == "param" {
-				parameter, err := q.parseFilterParam(matches[1], matches[2], value)
-				if err != nil {
-					return nil, err
-				}
-				arguments.SetArgument(parameter.Identification, parameter)
-			} else if (innerCategory == "binding") {
+			if (innerCategory == "binding") {
 				arguments.SetQueryBinding(value)
 			} else if (innerCategory == "order") {
 				arguments.AddOrder(value)
@@ -50,29 +84,75 @@ func (q *Query) parseFilterArguments(values *url.Values) (*ValueFilterArguments,
 		}
 	}
 
-	return arguments, nil
+	return arguments, invalidAliases
 }
 
 // parseFilterParam takes the basic configuration and generates a filter parameter.
-func (q *Query) parseFilterParam(paramName, remainingKeyData, value string) (*definition.Parameter, error) {
-	remainingDataMatches := fieldFilter.FindStringSubmatch(remainingKeyData)
-	operation := defaultOperation
-	possibleRemainingAlias := ""
-	if remainingDataMatches != nil {
-		operation = remainingDataMatches[1]
-		possibleRemainingAlias = remainingDataMatches[2]
-	}
-	remainingAliasMatches := fieldFilter.FindStringSubmatch(possibleRemainingAlias)
-	alias := paramName
-	if remainingAliasMatches != nil {
-		alias = remainingAliasMatches[1]
+// If the key has been passed multiple times the last value is used unless the
+// filter expects a list of values.
+func (q *Query) parseFilterParam(key *paramKey, values []string) (*definition.Parameter, error) {
+	value := values[len(values)-1]
+	paramName, operation := key.name, key.operation
+	for _, name := range []string{paramName, key.alias} {
+		if !binding.ValidName(name, q.isNameChar) {
+			return nil, NewInvalidNameError(name)
+		}
 	}
-	parameter := definition.NewParameter(alias)
+	parameter := definition.NewParameter(key.alias)
 	parameter.Name = paramName
-	parameter.Filter = q.getFilter(operation)
 	parameter.Value = value
+	if path := definition.SplitPath(paramName); len(path) > 1 {
+		parameter.Path = path
+	}
+	var field *definition.Field
+	if q.restrictsFields() {
+		field, parameter.Relationships = q.resolvePath(parameter.GetPath())
+		if field == nil {
+			return nil, NewUnknownFieldError(paramName)
+		}
+		parameter.BackendName = field.BackendName
+	}
+	parameter.Filter = q.getFieldFilter(paramName, field, operation)
 	if parameter.Filter == nil {
-		return nil, NewUnsupportedOperation(operation)
+		return nil, NewUnsupportedFieldOperation(paramName, operation)
+	}
+	fieldType := q.getFieldType(paramName)
+	if parameter.Filter.Untyped {
+		fieldType = nil
+	}
+	switch parameter.Filter.Arity {
+	case definition.ArityNone:
+		parameter.Value = nil
+	case definition.ArityList:
+		if err := checkLimit(LimitListSize, q.limits.MaxListSize, q.listSize(key, values)); err != nil {
+			return nil, err
+		}
+		items := q.listItems(key, values)
+		list, err := convertList(paramName, items, fieldType)
+		if err != nil {
+			return nil, err
+		}
+		parameter.Value = list
+	case definition.ArityRange:
+		items := q.listItems(key, values)
+		if len(items) != 2 {
+			return nil, NewValueConversionError(paramName, value, "range", fmt.Errorf("Expected 2 values, got %d", len(items)))
+		}
+		converted, err := convertList(paramName, items, fieldType)
+		if err != nil {
+			return nil, err
+		}
+		reflected := reflect.ValueOf(converted)
+		parameter.Value = &definition.Range{
+			From: reflected.Index(0).Interface(),
+			To:   reflected.Index(1).Interface(),
+		}
+	default:
+		converted, err := convertValue(paramName, value, fieldType)
+		if err != nil {
+			return nil, err
+		}
+		parameter.Value = converted
 	}
 
 	return parameter, nil
@@ -88,6 +168,133 @@ func (q *Query) getFilter(name string) *definition.Filter {
 	return nil
 }
 
+// listSize returns the amount of entries listItems returns without
+// splitting the value, so limits can be checked first.
+func (q *Query) listSize(key *paramKey, values []string) int {
+	if key.list || len(values) > 1 {
+		return len(values)
+	}
+	return countList(values[0], q.getListSeparator(), q.getListEscape())
+}
+
+// listItems returns the single entries of a list value. Keys ending with
+// "[]" or passed multiple times provide one entry per value, otherwise the
+// value is split by the configured separator.
+func (q *Query) listItems(key *paramKey, values []string) []string {
+	if key.list || len(values) > 1 {
+		return values
+	}
+	return splitList(values[0], q.getListSeparator(), q.getListEscape())
+}
+
+// getFieldFilter returns the filter with the given name which is allowed
+// for the field with the passed name. If the field has no own filters
+// configured the filters enabled for its name or the globally registered
+// filters are used.
+func (q *Query) getFieldFilter(fieldName string, field *definition.Field, name string) *definition.Filter {
+	if field != nil && field.HasFilters() {
+		return field.GetFilter(name)
+	}
+	if filters, ok := q.fieldFilters[fieldName]; ok && len(filters) > 0 {
+		for _, filter := range filters {
+			if filter.Identification == name {
+				return filter
+			}
+		}
+		return nil
+	}
+	return q.getFilter(name)
+}
+
+// getFieldType returns the type of the field with the given name. The type
+// of a registered field takes precedence over one set by name only.
+func (q *Query) getFieldType(name string) definition.FieldType {
+	if field, _ := q.resolvePath(definition.SplitPath(name)); field != nil && field.Type != nil {
+		return field.Type
+	}
+	return q.fieldTypes[name]
+}
+
+// getField returns the registered field with the given name.
+func (q *Query) getField(name string) *definition.Field {
+	for _, field := range q.fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// resolvePath returns the registered field the path points to and the
+// relationships which are traversed. The field is nil if the path isn't
+// registered.
+func (q *Query) resolvePath(path []string) (*definition.Field, []*definition.Relationship) {
+	field, relationships := definition.ResolvePath(path, q.fields, q.relationships)
+	if len(relationships) == 0 {
+		relationships = nil
+	}
+	return field, relationships
+}
+
+// restrictsFields returns if only registered fields are allowed.
+func (q *Query) restrictsFields() bool {
+	return len(q.fields) > 0 || len(q.relationships) > 0
+}
+
+// applyFields validates the orders against the registered fields and
+// sets their backend names and relationships. Dotted names may only
+// traverse to-one relationships as every entry needs a single value to be
+// ordered by. Errors are added to the collector with the given query key.
+func (q *Query) applyFields(orders []*definition.Order, key string, errs *errorCollector) {
+	if !q.restrictsFields() {
+		return
+	}
+	for _, order := range orders {
+		field, relationships := q.resolvePath(definition.SplitPath(order.GetOrderBy()))
+		if field == nil || !toOne(relationships) {
+			if errs.add(NewUnknownOrderError(order.GetOrderBy()), key) {
+				return
+			}
+			continue
+		}
+		order.SetBackendName(field.BackendName)
+		order.SetRelationships(relationships)
+	}
+}
+
+// toOne returns if all relationships are to-one relationships.
+func toOne(relationships []*definition.Relationship) bool {
+	for _, relationship := range relationships {
+		if !relationship.ToOne {
+			return false
+		}
+	}
+	return true
+}
+
+// getListSeparator returns the separator of list values.
+func (q *Query) getListSeparator() rune {
+	if q.listSeparator == 0 {
+		return defaultListSeparator
+	}
+	return q.listSeparator
+}
+
+// getListEscape returns the escape character of list values.
+func (q *Query) getListEscape() rune {
+	if q.listEscape == 0 {
+		return defaultListEscape
+	}
+	return q.listEscape
+}
+
+// setListFormat is used by the builder to configure how list values
+// are split.
+func (q *Query) setListFormat(separator, escape rune) {
+	q.listSeparator = separator
+	q.listEscape = escape
+}
+
 // GetDefaultOperation returns the default operation as the
 // entry.
 func (q *Query) GetDefaultOperation() string {
@@ -104,29 +311,177 @@ func (q *Query) setDefaultOperation(operation string) {
 	q.defaultOperation = operation
 }
 
+// setCollectErrors is used by the builder to configure if all errors
+// should be collected.
+func (q *Query) setCollectErrors(collect bool) {
+	q.collectErrors = collect
+}
+
+// setBindingShape is used by the builder to configure the tree shape
+// of the default binding.
+func (q *Query) setBindingShape(shape BindingShape) {
+	q.bindingShape = shape
+}
+
+// setLimits is used by the builder to configure the complexity limits.
+func (q *Query) setLimits(limits Limits) {
+	q.limits = limits
+}
+
+// setNameChars is used by the builder to configure the characters allowed
+// in names.
+func (q *Query) setNameChars(isNameChar func(rune) bool) {
+	q.isNameChar = isNameChar
+}
+
+// setRelationships is used by the builder to register the relationships
+// which may be filtered.
+func (q *Query) setRelationships(relationships []*definition.Relationship) {
+	q.relationships = relationships
+}
+
+// setSortParameter is used by the builder to configure the JSON:API sort
+// parameter.
+func (q *Query) setSortParameter(name string) {
+	q.sortParameter = name
+}
+
+// setPagination is used by the builder to enable the page parameters.
+func (q *Query) setPagination(pagination *Pagination) {
+	q.pagination = pagination
+}
+
+// setKeyset is used by the builder to enable cursor pagination.
+func (q *Query) setKeyset(keyset *keyset) {
+	q.keyset = keyset
+}
+
+// setResourceType is used by the builder to enable sparse fieldsets and
+// includes.
+func (q *Query) setResourceType(resourceType string) {
+	q.resourceType = resourceType
+}
+
+// setFields is used by the builder to restrict the allowed
+// field names.
+func (q *Query) setFields(fields []*definition.Field) {
+	q.fields = fields
+}
+
+// setFieldFilters is used by the builder to pass the filters of fields
+// which are configured by name only.
+func (q *Query) setFieldFilters(fieldFilters map[string][]*definition.Filter) {
+	q.fieldFilters = fieldFilters
+}
+
+// setFieldTypes is used by the builder to pass the types of fields which
+// are configured by name only.
+func (q *Query) setFieldTypes(fieldTypes map[string]definition.FieldType) {
+	q.fieldTypes = fieldTypes
+}
+
 // Parse takes the given values and returns the parsed data which is provided
 // by the Go struct.
+// If the query collects errors all problems are returned as ParseErrors.
 func (q *Query) Parse(values *url.Values) (*QueryData, error) {
-	arguments, err := q.parseFilterArguments(values)
+	errs := newErrorCollector(q.collectErrors)
+	arguments, invalidAliases := q.parseFilterArguments(values, errs)
+	if arguments == nil {
+		return nil, errs.err()
+	}
 
-	if err != nil {
-		return nil, err
+	explicitBinding := arguments.HasQueryBinding()
+	if explicitBinding {
+		if err := q.limits.checkBinding(arguments.GetQueryBinding()); err != nil {
+			errs.add(err, "filter[binding]")
+			return nil, errs.err()
+		}
+	} else {
+		arguments.SetQueryBinding(arguments.ConstructShapedQueryBinding(q.bindingShape))
+	}
+	var filter interface{}
+	if len(arguments.arguments) > 0 || explicitBinding {
+		var bindingErrs []error
+		filter, bindingErrs = arguments.parsedBinding(q.collectErrors, binding.NameChars(q.isNameChar))
+		for _, err := range bindingErrs {
+			if notFoundErr, ok := err.(*ParamNotFoundError); ok && invalidAliases[notFoundErr.ParamName] {
+				continue
+			}
+			if errs.add(err, "filter[binding]") {
+				return nil, errs.err()
+			}
+		}
 	}
 
-	if !arguments.HasQueryBinding() {
-		arguments.SetQueryBinding(arguments.ConstructDefaultQueryBinding())
+	var sortValues []string
+	if len(q.sortParameter) > 0 {
+		sortValues = (*values)[q.sortParameter]
+	}
+	orderCount := len(arguments.GetOrders()) + len(commaEntries(sortValues))
+	if err := checkLimit(LimitOrders, q.limits.MaxOrders, orderCount); err != nil {
+		key := "filter[order]"
+		if len(arguments.GetOrders()) == 0 {
+			key = q.sortParameter
+		}
+		errs.add(err, key)
+		return nil, errs.err()
+	}
+	orders, orderErrs := arguments.applyOrders(q.isNameChar)
+	for _, err := range orderErrs {
+		if errs.add(err, "filter[order]") {
+			return nil, errs.err()
+		}
+	}
+	q.applyFields(orders, "filter[order]", errs)
+	if len(sortValues) > 0 {
+		sortOrders, sortErrs := parseSort(sortValues, q.isNameChar)
+		for _, err := range sortErrs {
+			if errs.add(err, q.sortParameter) {
+				return nil, errs.err()
+			}
+		}
+		q.applyFields(sortOrders, q.sortParameter, errs)
+		orders = append(orders, sortOrders...)
 	}
-	var binding interface{}
-	if len(arguments.arguments) > 0 {
-		binding, err = arguments.ParsedBinding()
+	var cursor *Cursor
+	if q.keyset != nil {
+		orders = q.withTiebreaker(orders)
+		var err error
+		cursor, err = q.parseCursor(values, orders)
 		if err != nil {
-			return nil, err
+			key := pageAfterKey
+			if hasValue(values, pageBeforeKey) {
+				key = pageBeforeKey
+			}
+			errs.add(err, key)
+		} else if cursor != nil && cursor.Before {
+			orders = reverseOrders(orders)
 		}
 	}
+	var page *Page
+	if q.pagination != nil {
+		var err error
+		page, err = q.pagination.parsePage(values)
+		if err != nil {
+			errs.add(err, "page")
+		}
+	}
+	var fieldsets map[string]*Fieldset
+	var includes []string
+	if len(q.resourceType) > 0 && !errs.stopped() {
+		fieldsets = q.parseFieldsets(values, errs)
+		includes = q.parseIncludes(values, errs)
+	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
 
-	orders := arguments.ApplyOrders()
-
-	return NewQueryData(binding, orders), nil
+	queryData := NewQueryData(filter, orders).SetPage(page)
+	queryData.cursor = cursor
+	queryData.resourceType = q.resourceType
+	queryData.fieldsets = fieldsets
+	queryData.includes = includes
+	return queryData, nil
 }
 
 // newQuery uses the QueryBuilder to create a new Query entry.
@@ -12,11 +12,46 @@ type QueryData struct {
 	// Order is a sorted slice of order statements which should be
 	// sorted by.
 	order  []*definition.Order
+
+	// page is the requested page or nil if pagination isn't enabled.
+	page *Page
+
+	// cursor is the passed page[after] or page[before] cursor.
+	cursor *Cursor
+
+	// resourceType is the JSON:API type of the queried resource.
+	resourceType string
+
+	// fieldsets are the requested sparse fieldsets keyed by resource type.
+	fieldsets map[string]*Fieldset
+
+	// includes are the requested relationship paths.
+	includes []string
 }
 
-// GetFilter returns the parsed filter of the QueryData.
+// GetFilter returns the parsed filter of the QueryData. The seek predicate
+// of a passed cursor is added with an AND-Statement.
 func (q *QueryData) GetFilter() interface{} {
-	return q.filter
+	if q.cursor == nil {
+		return q.filter
+	}
+	if q.filter == nil {
+		return q.cursor.Predicate
+	}
+	and := definition.NewAnd()
+	and.Left = q.filter
+	and.Right = q.cursor.Predicate
+	return and
+}
+
+// GetFilterNode returns the parsed filter as a node of the filter tree or
+// nil if no filter has been given.
+func (q *QueryData) GetFilterNode() definition.Node {
+	node, ok := definition.AsNode(q.GetFilter())
+	if !ok {
+		return nil
+	}
+	return node
 }
 
 // GetOrders returns a list of all items which the query should
@@ -25,6 +60,57 @@ func (q *QueryData) GetOrders() []*definition.Order {
 	return q.order
 }
 
+// GetPage returns the requested page. It is nil if pagination hasn't been
+// enabled on the query builder.
+func (q *QueryData) GetPage() *Page {
+	return q.page
+}
+
+// SetPage sets the requested page.
+func (q *QueryData) SetPage(page *Page) *QueryData {
+	q.page = page
+	return q
+}
+
+// GetCursor returns the passed page[after] or page[before] cursor or nil
+// if none has been passed.
+func (q *QueryData) GetCursor() *Cursor {
+	return q.cursor
+}
+
+// GetResourceType returns the JSON:API type of the queried resource. It is
+// empty if no resource type has been set on the query builder.
+func (q *QueryData) GetResourceType() string {
+	return q.resourceType
+}
+
+// GetFieldset returns the sparse fieldset requested for the resource type
+// or nil if all fields should be returned.
+func (q *QueryData) GetFieldset(resourceType string) *Fieldset {
+	return q.fieldsets[resourceType]
+}
+
+// GetFieldsets returns all requested sparse fieldsets keyed by their
+// resource type.
+func (q *QueryData) GetFieldsets() map[string]*Fieldset {
+	return q.fieldsets
+}
+
+// GetIncludes returns the requested relationship paths like
+// "comments.author".
+func (q *QueryData) GetIncludes() []string {
+	return q.includes
+}
+
+// forwardOrders returns the orders without the reversal of a page[before]
+// cursor.
+func (q *QueryData) forwardOrders() []*definition.Order {
+	if q.cursor != nil && q.cursor.Before {
+		return reverseOrders(q.order)
+	}
+	return q.order
+}
+
 // NewQueryData initializes a new QueryData struct with all filter information
 // of the parameter.
 func NewQueryData(filter interface{}, order []*definition.Order) *QueryData {
@@ -1,8 +1,12 @@
 package filterparams
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"net/url"
+	"strconv"
+	"strings"
 
 	. "gopkg.in/check.v1"
 
@@ -48,9 +52,17 @@ func (t *QueryTest) TestGetFilterArguments(c *C) {
 	c.Assert(parameter.Value, Equals, "123456789")
 }
 
+// run parses the current data. Every parsed query has to survive a round
+// trip through the encoder.
 func (t *QueryTest) run(c *C) *QueryData {
-	queryData, err := t.builder.CreateQuery().Parse(t.data)
+	query := t.builder.CreateQuery()
+	queryData, err := query.Parse(t.data)
 	c.Assert(err, IsNil)
+	encoded, err := query.Encode(queryData)
+	c.Assert(err, IsNil, Commentf("Encoding %v", *t.data))
+	reparsed, err := query.Parse(&encoded)
+	c.Assert(err, IsNil, Commentf("Parsing %v encoded from %v", encoded, *t.data))
+	c.Assert(reparsed, DeepEquals, queryData, Commentf("Round trip of %v", *t.data))
 	return queryData
 }
 
@@ -159,6 +171,23 @@ func (t *QueryTest) TestQueryWithExplicitBinding(c *C) {
 	c.Assert(rightData.Name, Equals, "date")
 }
 
+func (t *QueryTest) TestQueryWithNestedBinding(c *C) {
+	t.addFilterParam("a", "eq", "1")
+	t.addFilterParam("b", "eq", "2")
+	t.addFilterParam("c", "eq", "3")
+	t.data.Set("filter[binding]", "!(a|b)&((a&b)|c)")
+	and, ok := t.run(c).GetFilter().(*definition.And)
+	c.Assert(ok, Equals, true)
+	negate, ok := and.Left.(*definition.Negate)
+	c.Assert(ok, Equals, true)
+	_, ok = negate.Negated.(*definition.Or)
+	c.Assert(ok, Equals, true)
+	or, ok := and.Right.(*definition.Or)
+	c.Assert(ok, Equals, true)
+	_, ok = or.Left.(*definition.And)
+	c.Assert(ok, Equals, true)
+}
+
 func (t *QueryTest) TestQueryWithSetAlias(c *C) {
 	t.addAliasedFilterParam("name", "eq", "aliasedName", "smith")
 	queryData := t.run(c)
@@ -235,3 +264,672 @@ func (t *QueryTest) TestIgnoreOtherParams(c *C) {
 	_, ok := queryData.GetFilter().(*definition.Parameter)
 	c.Assert(ok, Equals, true)
 }
+
+func (t *QueryTest) TestFieldMapping(c *C) {
+	t.builder.EnableField("name", "user_name")
+	t.addNameParam()
+	t.addOrder("desc(name)")
+	queryData := t.run(c)
+	param, ok := queryData.GetFilter().(*definition.Parameter)
+	c.Assert(ok, Equals, true)
+	c.Assert(param.Name, Equals, "name")
+	c.Assert(param.BackendName, Equals, "user_name")
+	c.Assert(queryData.GetOrders()[0].GetBackendName(), Equals, "user_name")
+}
+
+func (t *QueryTest) TestFieldMappingWithBinding(c *C) {
+	t.builder.EnableField("name", "user_name")
+	t.addAliasedFilterParam("name", "eq", "aliasedName", "smith")
+	t.data.Set("filter[binding]", "!aliasedName")
+	queryData := t.run(c)
+	negate, ok := queryData.GetFilter().(*definition.Negate)
+	c.Assert(ok, Equals, true)
+	c.Assert(negate.Negated.(*definition.Parameter).GetBackendName(), Equals, "user_name")
+}
+
+func (t *QueryTest) TestUnknownField(c *C) {
+	t.builder.EnableField("name", "user_name")
+	t.addDateParam()
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	fieldErr, ok := err.(*UnknownFieldError)
+	c.Assert(ok, Equals, true)
+	c.Assert(fieldErr.Field, Equals, "date")
+}
+
+func (t *QueryTest) TestUnknownOrder(c *C) {
+	t.builder.EnableField("name", "user_name")
+	t.addNameParam()
+	t.addOrder("date")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	orderErr, ok := err.(*UnknownOrderError)
+	c.Assert(ok, Equals, true)
+	c.Assert(orderErr.OrderBy, Equals, "date")
+}
+
+func (t *QueryTest) TestFieldFilterOverridesGlobalFilters(c *C) {
+	t.builder.EnableFieldFilter("date", definition.FilterLt, definition.FilterGt)
+	t.addFilterParam("date", "lt", "2015-01-01")
+	queryData := t.run(c)
+	param, ok := queryData.GetFilter().(*definition.Parameter)
+	c.Assert(ok, Equals, true)
+	c.Assert(param.Filter, Equals, definition.FilterLt)
+}
+
+func (t *QueryTest) TestFieldFilterAllowsUnregisteredFields(c *C) {
+	t.builder.EnableFieldFilter("date", definition.FilterLt)
+	t.builder.SetFieldType("age", definition.TypeInt)
+	t.addFilterParam("name", "eq", "doe")
+	param, ok := t.run(c).GetFilter().(*definition.Parameter)
+	c.Assert(ok, Equals, true)
+	c.Assert(param.Name, Equals, "name")
+}
+
+func (t *QueryTest) TestFieldFilterUnsupported(c *C) {
+	t.builder.EnableFieldFilter("date", definition.FilterLt, definition.FilterGt)
+	t.builder.EnableFieldFilter("name", definition.FilterEq, definition.FilterLike)
+	t.addDateParam()
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	operationErr, ok := err.(*UnsupportedOperationError)
+	c.Assert(ok, Equals, true)
+	c.Assert(operationErr.Field, Equals, "date")
+	c.Assert(operationErr.Operation, Equals, "eq")
+	c.Assert(operationErr.Error(), Equals, "The operation \"eq\" is unsupported for the field \"date\"")
+}
+
+func (t *QueryTest) TestUnsupportedOperationNamesField(c *C) {
+	t.data.Set("filter[param][date][notSupported]", "2015-01-01")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	operationErr, ok := err.(*UnsupportedOperationError)
+	c.Assert(ok, Equals, true)
+	c.Assert(operationErr.Field, Equals, "date")
+}
+
+func (t *QueryTest) TestTypedValue(c *C) {
+	t.builder.EnableFilter(definition.FilterGt)
+	t.builder.SetFieldType("age", definition.TypeInt)
+	t.addFilterParam("age", "gt", "18")
+	queryData := t.run(c)
+	param, ok := queryData.GetFilter().(*definition.Parameter)
+	c.Assert(ok, Equals, true)
+	c.Assert(param.Value, Equals, int64(18))
+}
+
+func (t *QueryTest) TestTypedValueConversionError(c *C) {
+	t.builder.SetFieldType("age", definition.TypeInt)
+	t.addFilterParam("age", "eq", "eighteen")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	conversionErr, ok := err.(*ValueConversionError)
+	c.Assert(ok, Equals, true)
+	c.Assert(conversionErr.Parameter, Equals, "age")
+	c.Assert(conversionErr.Value, Equals, "eighteen")
+	c.Assert(conversionErr.ExpectedType, Equals, "int")
+	c.Assert(conversionErr.Err, NotNil)
+	c.Assert(errors.Is(err, strconv.ErrSyntax), Equals, true)
+}
+
+func (t *QueryTest) TestTypedValuePattern(c *C) {
+	t.builder.EnableFilter(definition.FilterLike)
+	t.builder.SetFieldType("age", definition.TypeInt)
+	t.addFilterParam("age", "like", "1%")
+	param := t.run(c).GetFilter().(*definition.Parameter)
+	c.Assert(param.Value, Equals, "1%")
+}
+
+func (t *QueryTest) TestInList(c *C) {
+	t.builder.EnableFilter(definition.FilterIn)
+	t.addFilterParam("name", "in", "smith,doe\\,john")
+	queryData := t.run(c)
+	param := queryData.GetFilter().(*definition.Parameter)
+	c.Assert(param.Value, DeepEquals, []string{"smith", "doe,john"})
+}
+
+func (t *QueryTest) TestInListTrailingEscape(c *C) {
+	t.builder.EnableFilter(definition.FilterIn)
+	t.addFilterParam("name", "in", "smith,doe\\")
+	queryData := t.run(c)
+	param := queryData.GetFilter().(*definition.Parameter)
+	c.Assert(param.Value, DeepEquals, []string{"smith", "doe\\"})
+}
+
+func (t *QueryTest) TestInListRepeatedKeys(c *C) {
+	t.builder.EnableFilter(definition.FilterIn)
+	t.data.Add("filter[param][name][in][]", "smith")
+	t.data.Add("filter[param][name][in][]", "doe,john")
+	queryData := t.run(c)
+	param := queryData.GetFilter().(*definition.Parameter)
+	c.Assert(param.Value, DeepEquals, []string{"smith", "doe,john"})
+}
+
+func (t *QueryTest) TestInListRepeatedAliasedKeys(c *C) {
+	t.builder.EnableFilter(definition.FilterIn)
+	t.data.Add("filter[param][name][in][names][]", "smith")
+	t.data.Add("filter[param][name][in][names][]", "doe")
+	queryData := t.run(c)
+	param := queryData.GetFilter().(*definition.Parameter)
+	c.Assert(param.Identification, Equals, "names")
+	c.Assert(param.Value, DeepEquals, []string{"smith", "doe"})
+}
+
+func (t *QueryTest) TestInListEmpty(c *C) {
+	t.builder.EnableFilter(definition.FilterIn)
+	t.addFilterParam("name", "in", "")
+	queryData := t.run(c)
+	param := queryData.GetFilter().(*definition.Parameter)
+	c.Assert(param.Value, DeepEquals, []string{})
+}
+
+func (t *QueryTest) TestInListTyped(c *C) {
+	t.builder.EnableFilter(definition.FilterIn)
+	t.builder.SetFieldType("age", definition.TypeInt)
+	t.addFilterParam("age", "in", "18,21")
+	queryData := t.run(c)
+	param := queryData.GetFilter().(*definition.Parameter)
+	c.Assert(param.Value, DeepEquals, []int64{18, 21})
+}
+
+func (t *QueryTest) TestInListTypedError(c *C) {
+	t.builder.EnableFilter(definition.FilterIn)
+	t.builder.SetFieldType("age", definition.TypeInt)
+	t.addFilterParam("age", "in", "18,twenty")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	conversionErr, ok := err.(*ValueConversionError)
+	c.Assert(ok, Equals, true)
+	c.Assert(conversionErr.Value, Equals, "twenty")
+}
+
+func (t *QueryTest) TestInListSeparator(c *C) {
+	t.builder.EnableFilter(definition.FilterIn)
+	t.builder.SetListSeparator(';').SetListEscape('~')
+	t.addFilterParam("name", "in", "smith;doe,john~;jr")
+	queryData := t.run(c)
+	param := queryData.GetFilter().(*definition.Parameter)
+	c.Assert(param.Value, DeepEquals, []string{"smith", "doe,john;jr"})
+}
+
+func (t *QueryTest) TestRange(c *C) {
+	t.builder.EnableFilter(&definition.Filter{Identification: "between", Arity: definition.ArityRange})
+	t.builder.SetFieldType("age", definition.TypeInt)
+	t.addFilterParam("age", "between", "18,30")
+	queryData := t.run(c)
+	param := queryData.GetFilter().(*definition.Parameter)
+	c.Assert(param.Value, DeepEquals, &definition.Range{From: int64(18), To: int64(30)})
+}
+
+func (t *QueryTest) TestNullCheck(c *C) {
+	t.builder.EnableFilter(definition.FilterIsNull)
+	t.builder.SetFieldType("age", definition.TypeInt)
+	t.addFilterParam("age", "isnull", "")
+	param := t.run(c).GetFilter().(*definition.Parameter)
+	c.Assert(param.Filter, Equals, definition.FilterIsNull)
+	c.Assert(param.Value, IsNil)
+}
+
+func (t *QueryTest) TestRangeWrongLength(c *C) {
+	t.builder.EnableFilter(&definition.Filter{Identification: "between", Arity: definition.ArityRange})
+	t.addFilterParam("age", "between", "18,30,40")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	_, ok := err.(*ValueConversionError)
+	c.Assert(ok, Equals, true)
+}
+
+func (t *QueryTest) TestBindingSyntaxError(c *C) {
+	t.addNameAndDateParam()
+	t.data.Set("filter[binding]", "name & (date")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	syntaxErr, ok := err.(*BindingSyntaxError)
+	c.Assert(ok, Equals, true)
+	c.Assert(syntaxErr.Binding, Equals, "name & (date")
+	c.Assert(syntaxErr.Offset, Equals, 12)
+	c.Assert(syntaxErr.Line, Equals, 1)
+	c.Assert(syntaxErr.Column, Equals, 13)
+	c.Assert(syntaxErr.Expected, DeepEquals, []string{"&", ")", "|", "parameter"})
+}
+
+func (t *QueryTest) TestParamNotFoundPosition(c *C) {
+	t.addNameAndDateParam()
+	t.data.Set("filter[binding]", "name & (date | email)")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	notFoundErr, ok := err.(*ParamNotFoundError)
+	c.Assert(ok, Equals, true)
+	c.Assert(notFoundErr.ParamName, Equals, "email")
+	c.Assert(notFoundErr.Binding, Equals, "name & (date | email)")
+	c.Assert(notFoundErr.Position.Offset, Equals, 15)
+	c.Assert(notFoundErr.Position.Column, Equals, 16)
+}
+
+func (t *QueryTest) TestErrorKey(c *C) {
+	t.data.Set("filter[param][date][notSupported]", "2015-01-01")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	keyed, ok := err.(KeyedError)
+	c.Assert(ok, Equals, true)
+	c.Assert(keyed.QueryKey(), Equals, "filter[param][date][notSupported]")
+}
+
+func (t *QueryTest) TestCollectErrors(c *C) {
+	t.builder.SetCollectErrors(true)
+	t.builder.EnableField("name", "")
+	t.builder.EnableField("date", "")
+	t.builder.EnableField("age", "").SetFieldType("age", definition.TypeInt)
+	t.addNameParam()
+	t.data.Set("filter[param][date][notSupported][broken]", "2015-01-01")
+	t.data.Set("filter[param][age][eq]", "old")
+	t.data.Set("filter[param][email][eq]", "a@example.com")
+	t.data.Set("filter[binding]", "name&broken&(missing|!missing)")
+	t.addOrder("name")
+	t.addOrder("desc(unknown)")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	parseErrs, ok := err.(ParseErrors)
+	c.Assert(ok, Equals, true)
+	c.Assert(len(parseErrs), Equals, 5)
+
+	keys := make([]string, len(parseErrs))
+	for index, parseErr := range parseErrs {
+		keys[index] = parseErr.(KeyedError).QueryKey()
+	}
+	c.Assert(keys, DeepEquals, []string{
+		"filter[param][age][eq]",
+		"filter[param][date][notSupported][broken]",
+		"filter[param][email][eq]",
+		"filter[binding]",
+		"filter[order]",
+	})
+	_, ok = parseErrs[0].(*ValueConversionError)
+	c.Assert(ok, Equals, true)
+	_, ok = parseErrs[1].(*UnsupportedOperationError)
+	c.Assert(ok, Equals, true)
+	_, ok = parseErrs[2].(*UnknownFieldError)
+	c.Assert(ok, Equals, true)
+	c.Assert(parseErrs[3].(*ParamNotFoundError).ParamName, Equals, "missing")
+	c.Assert(parseErrs[4].(*UnknownOrderError).OrderBy, Equals, "unknown")
+}
+
+func (t *QueryTest) TestCollectErrorsAllParamsInvalid(c *C) {
+	t.builder.SetCollectErrors(true)
+	t.builder.EnableField("age", "").SetFieldType("age", definition.TypeInt)
+	t.data.Set("filter[param][age][eq]", "old")
+	t.data.Set("filter[binding]", "age|")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	parseErrs, ok := err.(ParseErrors)
+	c.Assert(ok, Equals, true)
+	c.Assert(parseErrs, HasLen, 2)
+	c.Assert(parseErrs[0].(KeyedError).QueryKey(), Equals, "filter[param][age][eq]")
+	c.Assert(parseErrs[1].(KeyedError).QueryKey(), Equals, "filter[binding]")
+
+	t.data.Set("filter[binding]", "age|missing")
+	_, err = t.builder.CreateQuery().Parse(t.data)
+	parseErrs = err.(ParseErrors)
+	c.Assert(parseErrs, HasLen, 2)
+	c.Assert(parseErrs[1].(*ParamNotFoundError).ParamName, Equals, "missing")
+}
+
+func (t *QueryTest) TestCollectErrorsWithoutErrors(c *C) {
+	t.builder.SetCollectErrors(true)
+	t.addNameParam()
+	queryData := t.run(c)
+	_, ok := queryData.GetFilter().(*definition.Parameter)
+	c.Assert(ok, Equals, true)
+}
+
+func (t *QueryTest) TestImplicitBindingIsDeterministic(c *C) {
+	t.addFilterParam("c", "eq", "3")
+	t.addFilterParam("a", "eq", "1")
+	t.addFilterParam("b", "eq", "2")
+	for run := 0; run < 10; run++ {
+		and := t.run(c).GetFilter().(*definition.And)
+		c.Assert(and.Left.(*definition.Parameter).Identification, Equals, "a")
+		right := and.Right.(*definition.And)
+		c.Assert(right.Left.(*definition.Parameter).Identification, Equals, "b")
+		c.Assert(right.Right.(*definition.Parameter).Identification, Equals, "c")
+	}
+}
+
+func (t *QueryTest) TestImplicitBindingLeftDeep(c *C) {
+	t.builder.SetDefaultBindingShape(BindingLeftDeep)
+	t.addFilterParam("c", "eq", "3")
+	t.addFilterParam("a", "eq", "1")
+	t.addFilterParam("b", "eq", "2")
+	and := t.run(c).GetFilter().(*definition.And)
+	c.Assert(and.Right.(*definition.Parameter).Identification, Equals, "c")
+	left := and.Left.(*definition.And)
+	c.Assert(left.Left.(*definition.Parameter).Identification, Equals, "a")
+	c.Assert(left.Right.(*definition.Parameter).Identification, Equals, "b")
+}
+
+func (t *QueryTest) expectLimitExceeded(c *C, limit Limit, key string) {
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	limitErr, ok := err.(*LimitExceededError)
+	c.Assert(ok, Equals, true, Commentf("Unexpected error %v", err))
+	c.Assert(limitErr.Limit, Equals, limit)
+	c.Assert(limitErr.QueryKey(), Equals, key)
+}
+
+func (t *QueryTest) TestMaxParameters(c *C) {
+	t.builder.SetMaxParameters(2)
+	t.addFilterParam("a", "eq", "1")
+	t.addFilterParam("b", "eq", "2")
+	t.run(c)
+	t.addFilterParam("c", "eq", "3")
+	t.expectLimitExceeded(c, LimitParameters, "filter[param]")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	c.Assert(err, ErrorMatches, `The number of parameters \(3\) exceeds the maximum of 2`)
+}
+
+func (t *QueryTest) TestMaxParametersCollectErrors(c *C) {
+	t.builder.SetMaxParameters(1).SetCollectErrors(true)
+	t.addFilterParam("a", "eq", "1")
+	t.addFilterParam("b", "eq", "2")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	parseErrs, ok := err.(ParseErrors)
+	c.Assert(ok, Equals, true)
+	c.Assert(parseErrs, HasLen, 1)
+	c.Assert(parseErrs[0].(*LimitExceededError).Actual, Equals, 2)
+}
+
+func (t *QueryTest) TestMaxBindingLength(c *C) {
+	t.builder.SetMaxBindingLength(3)
+	t.addFilterParam("a", "eq", "1")
+	t.addFilterParam("b", "eq", "2")
+	t.data.Set("filter[binding]", "a|b")
+	t.run(c)
+	t.data.Set("filter[binding]", "(a|b)")
+	t.expectLimitExceeded(c, LimitBindingLength, "filter[binding]")
+}
+
+func (t *QueryTest) TestMaxBindingDepth(c *C) {
+	t.builder.SetMaxBindingDepth(2)
+	t.addFilterParam("a", "eq", "1")
+	t.data.Set("filter[binding]", "!(a)")
+	t.run(c)
+	t.data.Set("filter[binding]", strings.Repeat("(", 1000)+"a"+strings.Repeat(")", 1000))
+	t.expectLimitExceeded(c, LimitBindingDepth, "filter[binding]")
+	t.data.Set("filter[binding]", "!!!a")
+	t.expectLimitExceeded(c, LimitBindingDepth, "filter[binding]")
+}
+
+func (t *QueryTest) TestMaxOrders(c *C) {
+	t.builder.SetMaxOrders(1)
+	t.data.Add("filter[order]", "name")
+	t.run(c)
+	t.data.Add("filter[order]", "desc(id)")
+	t.expectLimitExceeded(c, LimitOrders, "filter[order]")
+}
+
+func (t *QueryTest) TestMaxListSize(c *C) {
+	t.builder.EnableFilter(definition.FilterIn).SetMaxListSize(2)
+	t.data.Set("filter[param][id][in]", "1,2")
+	c.Assert(t.run(c).GetFilter().(*definition.Parameter).Value, DeepEquals, []string{"1", "2"})
+	t.data.Set("filter[param][id][in]", "1\\,2,3")
+	c.Assert(t.run(c).GetFilter().(*definition.Parameter).Value, DeepEquals, []string{"1,2", "3"})
+	t.data.Set("filter[param][id][in]", "1,2,3")
+	t.expectLimitExceeded(c, LimitListSize, "filter[param][id][in]")
+	t.data.Del("filter[param][id][in]")
+	(*t.data)["filter[param][id][in][]"] = []string{"1", "2", "3"}
+	t.expectLimitExceeded(c, LimitListSize, "filter[param][id][in][]")
+}
+
+func (t *QueryTest) TestCountList(c *C) {
+	for _, value := range []string{"", "a", "a,b", ",", "a\\,b", "a\\\\,b", "a,b\\"} {
+		c.Assert(countList(value, ',', '\\'), Equals, len(splitList(value, ',', '\\')), Commentf(value))
+	}
+}
+
+func (t *QueryTest) TestSetLimits(c *C) {
+	t.builder.SetLimits(Limits{MaxParameters: 1})
+	t.addFilterParam("a", "eq", "1")
+	t.addFilterParam("b", "eq", "2")
+	t.expectLimitExceeded(c, LimitParameters, "filter[param]")
+}
+
+func (t *QueryTest) TestUnicodeAndDottedNames(c *C) {
+	t.addFilterParam("item0", "eq", "1")
+	t.addFilterParam("größe", "eq", "2")
+	t.addAliasedFilterParam("address.city", "eq", "city", "Berlin")
+	t.data.Set("filter[binding]", "item0&(größe|city)")
+	t.addOrder("desc(address.city)")
+	queryData := t.run(c)
+	and := queryData.GetFilter().(*definition.And)
+	c.Assert(and.Left.(*definition.Parameter).Name, Equals, "item0")
+	or := and.Right.(*definition.Or)
+	c.Assert(or.Left.(*definition.Parameter).Name, Equals, "größe")
+	c.Assert(or.Right.(*definition.Parameter).Name, Equals, "address.city")
+	c.Assert(or.Right.(*definition.Parameter).Value, Equals, "Berlin")
+	c.Assert(queryData.GetOrders()[0].GetOrderBy(), Equals, "address.city")
+}
+
+func (t *QueryTest) TestInvalidName(c *C) {
+	t.addFilterParam("a b", "eq", "1")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	nameErr, ok := err.(*InvalidNameError)
+	c.Assert(ok, Equals, true)
+	c.Assert(nameErr.Name, Equals, "a b")
+	c.Assert(nameErr.QueryKey(), Equals, "filter[param][a b][eq]")
+}
+
+func (t *QueryTest) TestInvalidAlias(c *C) {
+	t.addAliasedFilterParam("name", "eq", "na$me", "1")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	c.Assert(err.(*InvalidNameError).Name, Equals, "na$me")
+}
+
+func (t *QueryTest) TestInvalidDottedPath(c *C) {
+	t.addFilterParam("address..city", "eq", "1")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	c.Assert(err, FitsTypeOf, &InvalidNameError{})
+}
+
+func (t *QueryTest) TestInvalidOrderName(c *C) {
+	t.addOrder("desc(na$me)")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	nameErr, ok := err.(*InvalidNameError)
+	c.Assert(ok, Equals, true)
+	c.Assert(nameErr.Name, Equals, "na$me")
+	c.Assert(nameErr.QueryKey(), Equals, "filter[order]")
+}
+
+func (t *QueryTest) TestNameChars(c *C) {
+	t.builder.SetNameChars(func(char rune) bool {
+		return char >= 'a' && char <= 'z' || char == ':'
+	})
+	t.addFilterParam("ns:name", "eq", "1")
+	t.addFilterParam("other", "eq", "2")
+	t.data.Set("filter[binding]", "ns:name|other")
+	t.addOrder("ns:name")
+	queryData := t.run(c)
+	c.Assert(queryData.GetFilter().(*definition.Or).Left.(*definition.Parameter).Name, Equals, "ns:name")
+
+	t.addFilterParam("größe", "eq", "3")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	c.Assert(err.(*InvalidNameError).Name, Equals, "größe")
+}
+
+func (t *QueryTest) TestRelationshipPath(c *C) {
+	author := definition.NewRelationship("author", "users", "author_id", "id").EnableField("name", "full_name")
+	t.builder.EnableField("title", "").AddRelationship(author)
+	t.addFilterParam("author.name", "like", "Jo%")
+	parameter := t.run(c).GetFilter().(*definition.Parameter)
+	c.Assert(parameter.Name, Equals, "author.name")
+	c.Assert(parameter.Path, DeepEquals, []string{"author", "name"})
+	c.Assert(parameter.BackendName, Equals, "full_name")
+	c.Assert(parameter.Relationships, DeepEquals, []*definition.Relationship{author})
+}
+
+func (t *QueryTest) TestUnknownRelationshipPath(c *C) {
+	author := definition.NewRelationship("author", "users", "author_id", "id").EnableField("name", "")
+	t.builder.AddRelationship(author)
+	for _, name := range []string{"author.email", "editor.name", "author", "name"} {
+		t.data = &url.Values{}
+		t.addFilterParam(name, "eq", "1")
+		_, err := t.builder.CreateQuery().Parse(t.data)
+		c.Assert(err, FitsTypeOf, &UnknownFieldError{}, Commentf("%s", name))
+	}
+}
+
+func (t *QueryTest) TestRelationshipOrder(c *C) {
+	author := definition.NewRelationship("author", "users", "author_id", "id").SetToOne(true).EnableField("name", "full_name")
+	comments := definition.NewRelationship("comments", "comments", "id", "post_id").EnableField("text", "")
+	t.builder.EnableField("title", "").AddRelationship(author).AddRelationship(comments)
+	t.addOrder("desc(author.name)")
+	order := t.run(c).GetOrders()[0]
+	c.Assert(order.GetBackendName(), Equals, "full_name")
+	c.Assert(order.GetRelationships(), DeepEquals, []*definition.Relationship{author})
+	c.Assert(order.Reverse().GetRelationships(), DeepEquals, []*definition.Relationship{author})
+
+	for _, name := range []string{"comments.text", "author.email"} {
+		t.data.Set("filter[order]", name)
+		_, err := t.builder.CreateQuery().Parse(t.data)
+		c.Assert(err, FitsTypeOf, &UnknownOrderError{}, Commentf("%s", name))
+	}
+}
+
+func (t *QueryTest) TestUnrestrictedPath(c *C) {
+	t.addFilterParam("author.name", "eq", "doe")
+	parameter := t.run(c).GetFilter().(*definition.Parameter)
+	c.Assert(parameter.GetPath(), DeepEquals, []string{"author", "name"})
+	c.Assert(parameter.Relationships, IsNil)
+}
+
+func (t *QueryTest) TestOrderNulls(c *C) {
+	t.addOrder("desc(name,nullslast)")
+	t.addOrder("asc(date, nullsfirst)")
+	orders := t.run(c).GetOrders()
+	c.Assert(orders, HasLen, 2)
+	c.Assert(orders[0].OrderDesc(), Equals, true)
+	c.Assert(orders[0].GetNulls(), Equals, definition.NullsLast)
+	c.Assert(orders[1].OrderDesc(), Equals, false)
+	c.Assert(orders[1].GetNulls(), Equals, definition.NullsFirst)
+}
+
+func (t *QueryTest) TestInvalidOrder(c *C) {
+	t.addOrder("desc(name,nullsmiddle)")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	orderErr, ok := err.(*InvalidOrderError)
+	c.Assert(ok, Equals, true)
+	c.Assert(orderErr.Order, Equals, "desc(name,nullsmiddle)")
+	c.Assert(orderErr.QueryKey(), Equals, "filter[order]")
+}
+
+func (t *QueryTest) TestSortParameter(c *C) {
+	t.data.Set("sort", "-name,first_name")
+	c.Assert(t.run(c).GetOrders(), HasLen, 0)
+
+	t.builder.SetSortParameter("sort")
+	t.addOrder("date")
+	orders := t.run(c).GetOrders()
+	c.Assert(orders, DeepEquals, []*definition.Order{
+		definition.NewOrderAsc("date"),
+		definition.NewOrderDesc("name"),
+		definition.NewOrderAsc("first_name"),
+	})
+}
+
+func (t *QueryTest) TestSortParameterUnknownField(c *C) {
+	t.builder.SetSortParameter("sort").EnableField("name", "user_name")
+	t.data.Set("sort", "-name,unknown")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	orderErr, ok := err.(*UnknownOrderError)
+	c.Assert(ok, Equals, true)
+	c.Assert(orderErr.OrderBy, Equals, "unknown")
+	c.Assert(orderErr.QueryKey(), Equals, "sort")
+}
+
+func (t *QueryTest) TestSortParameterLimit(c *C) {
+	t.builder.SetSortParameter("sort").SetMaxOrders(2)
+	t.data.Set("sort", "a,b,c")
+	t.expectLimitExceeded(c, LimitOrders, "sort")
+}
+
+func (t *QueryTest) TestPaginationDisabled(c *C) {
+	t.data.Set("page[number]", "2")
+	c.Assert(t.run(c).GetPage(), IsNil)
+}
+
+func (t *QueryTest) TestPaginationDefault(c *C) {
+	t.builder.EnablePagination(20, 100)
+	c.Assert(t.run(c).GetPage(), DeepEquals, &Page{Offset: 0, Limit: 20})
+}
+
+func (t *QueryTest) TestPaginationNumberAndSize(c *C) {
+	t.builder.EnablePagination(20, 100)
+	t.data.Set("page[number]", "3")
+	c.Assert(t.run(c).GetPage(), DeepEquals, &Page{Offset: 40, Limit: 20})
+	t.data.Set("page[size]", "50")
+	c.Assert(t.run(c).GetPage(), DeepEquals, &Page{Offset: 100, Limit: 50})
+}
+
+func (t *QueryTest) TestPaginationOffsetAndLimit(c *C) {
+	t.builder.EnablePagination(20, 100)
+	t.data.Set("page[offset]", "15")
+	c.Assert(t.run(c).GetPage(), DeepEquals, &Page{Offset: 15, Limit: 20})
+	t.data.Set("page[limit]", "5")
+	c.Assert(t.run(c).GetPage(), DeepEquals, &Page{Offset: 15, Limit: 5})
+}
+
+func (t *QueryTest) TestPaginationWithoutDefaultSize(c *C) {
+	t.builder.EnablePagination(0, 0)
+	c.Assert(t.run(c).GetPage(), DeepEquals, &Page{})
+	t.data.Set("page[number]", "2")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	pageErr, ok := err.(*InvalidPageError)
+	c.Assert(ok, Equals, true, Commentf("Unexpected error %v", err))
+	c.Assert(pageErr.QueryKey(), Equals, "page[number]")
+}
+
+func (t *QueryTest) TestPaginationMaxSize(c *C) {
+	t.builder.EnablePagination(20, 100)
+	t.data.Set("page[size]", "101")
+	t.expectLimitExceeded(c, LimitPageSize, "page[size]")
+	t.data.Del("page[size]")
+	t.data.Set("page[limit]", "500")
+	t.expectLimitExceeded(c, LimitPageSize, "page[limit]")
+}
+
+func (t *QueryTest) TestPaginationInvalidValues(c *C) {
+	t.builder.EnablePagination(20, 100)
+	for key, value := range map[string]string{
+		"page[number]": "0",
+		"page[size]":   "abc",
+		"page[offset]": "-1",
+		"page[limit]":  "0",
+	} {
+		t.data = &url.Values{key: {value}}
+		_, err := t.builder.CreateQuery().Parse(t.data)
+		pageErr, ok := err.(*InvalidPageError)
+		c.Assert(ok, Equals, true, Commentf("Unexpected error %v for %s", err, key))
+		c.Assert(pageErr.Value, Equals, value)
+		c.Assert(pageErr.QueryKey(), Equals, key)
+	}
+}
+
+func (t *QueryTest) TestPaginationNumberOverflow(c *C) {
+	t.builder.EnablePagination(20, 0)
+	t.data.Set("page[size]", "1000")
+	t.data.Set("page[number]", strconv.Itoa(math.MaxInt/1000+2))
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	pageErr, ok := err.(*InvalidPageError)
+	c.Assert(ok, Equals, true, Commentf("Unexpected error %v", err))
+	c.Assert(pageErr.QueryKey(), Equals, "page[number]")
+
+	t.data.Set("page[number]", strconv.Itoa(math.MaxInt/1000+1))
+	c.Assert(t.run(c).GetPage().Offset, Equals, math.MaxInt/1000*1000)
+}
+
+func (t *QueryTest) TestPaginationMixedStyles(c *C) {
+	t.builder.EnablePagination(20, 100)
+	t.data.Set("page[number]", "2")
+	t.data.Set("page[limit]", "10")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	pageErr, ok := err.(*InvalidPageError)
+	c.Assert(ok, Equals, true, Commentf("Unexpected error %v", err))
+	c.Assert(pageErr.QueryKey(), Equals, "page[limit]")
+}
+
+func (t *QueryTest) TestPaginationCollectErrors(c *C) {
+	t.builder.EnablePagination(20, 100).SetCollectErrors(true)
+	t.addFilterParam("name", "gt", "a")
+	t.data.Set("page[size]", "abc")
+	_, err := t.builder.CreateQuery().Parse(t.data)
+	parseErrs, ok := err.(ParseErrors)
+	c.Assert(ok, Equals, true)
+	c.Assert(parseErrs, HasLen, 2)
+	c.Assert(parseErrs[1], FitsTypeOf, &InvalidPageError{})
+}
@@ -2,20 +2,22 @@ package filterparams
 
 import (
 	"fmt"
-	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/cbrand/go-filterparams/binding"
 	"github.com/cbrand/go-filterparams/definition"
 )
 
-// orderMatcher is used to verify
-var orderMatcher = regexp.MustCompile("(?:(asc|desc)\\(([a-zA-Z0-9_\\-]*)\\)|([a-zA-Z0-9_\\-]*))")
-
 // ParamNotFoundError represents a parameter which is specified
 // in the query.
 type ParamNotFoundError struct {
+	errorKey
 	ParamName string
+	// Binding is the binding string which references the parameter.
+	Binding string
+	// Position is the position of the parameter in the binding string.
+	Position binding.Position
 }
 
 // Error returns the string representation of the given error.
@@ -83,56 +85,220 @@ func (v *ValueFilterArguments) GetOrders() []string {
 
 // ParsedBinding parses the order string and returns the parsed result.
 func (v *ValueFilterArguments) ParsedBinding() (interface{}, error) {
-	data, err := binding.ParseString(v.queryBinding)
+	data, errs := v.parsedBinding(false)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return data, nil
+}
+
+// parsedBinding parses the binding and returns the errors. If collect is
+// set all missing parameters are reported, otherwise only the first one.
+func (v *ValueFilterArguments) parsedBinding(collect bool, opts ...binding.Option) (interface{}, []error) {
+	data, err := binding.ParseString(v.queryBinding, opts...)
 	if err != nil {
-		return nil, err
+		if syntaxErr, ok := err.(*binding.SyntaxError); ok {
+			return nil, []error{NewBindingSyntaxError(syntaxErr)}
+		}
+		return nil, []error{err}
 	}
+	errs := []error{}
+	reported := map[string]bool{}
 	parameters := data.(definition.ParameterHaver).GetParameters()
 	for _, parameter := range parameters {
 		argument := v.GetArgument(parameter.Identification)
 		if argument == nil {
-			return nil, NewFilterParamNotFoundError(parameter.Identification)
+			if reported[parameter.Identification] {
+				continue
+			}
+			reported[parameter.Identification] = true
+			notFoundErr := NewFilterParamNotFoundError(parameter.Identification)
+			notFoundErr.Binding = v.queryBinding
+			notFoundErr.Position, _ = binding.Locate(v.queryBinding, parameter.Identification)
+			errs = append(errs, notFoundErr)
+			if !collect {
+				return nil, errs
+			}
+			continue
 		}
 
-		parameter.Name = argument.Name
-		parameter.Value = argument.Value
-		parameter.Filter = argument.Filter
+		*parameter = *argument
 	}
 
+	if len(errs) > 0 {
+		return nil, errs
+	}
 	return data, nil
 }
 
 // ApplyOrders takes the configured orders and returns the configured
-// order objects.
+// order objects. Orders with invalid names are skipped.
 func (v *ValueFilterArguments) ApplyOrders() []*definition.Order {
+	orders, _ := v.applyOrders(nil)
+	return orders
+}
+
+// applyOrders returns the configured order objects and the errors of
+// orders with an invalid syntax or a name containing characters not
+// accepted by isNameChar. Empty orders are ignored.
+func (v *ValueFilterArguments) applyOrders(isNameChar func(rune) bool) ([]*definition.Order, []error) {
 	orders := []*definition.Order{}
+	errs := []error{}
 	for _, orderString := range v.GetOrders() {
-		matches := orderMatcher.FindStringSubmatch(orderString)
-		if len(matches) == 0 {
+		name, ascDesc, nulls, err := parseOrder(orderString)
+		if err != nil {
+			errs = append(errs, err)
 			continue
 		}
-		ascDesc := "asc"
-		name := matches[3]
-		if len(matches[3]) == 0 {
-			ascDesc = matches[1]
-			name = matches[2]
+		if len(name) == 0 {
+			continue
 		}
-		orders = append(orders, definition.NewOrder(name, ascDesc))
+		order, err := newOrder(name, ascDesc, nulls, isNameChar)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		orders = append(orders, order)
 	}
-	return orders
+	return orders, errs
+}
+
+// parseOrder splits an order like "name", "asc(name)", "desc(name)" or
+// "desc(name,nullslast)" into the name, the direction and the placement
+// of null values.
+func parseOrder(order string) (string, string, definition.Nulls, error) {
+	order = strings.TrimSpace(order)
+	for _, direction := range []string{"asc", "desc"} {
+		if !strings.HasPrefix(order, direction+"(") || !strings.HasSuffix(order, ")") {
+			continue
+		}
+		arguments := strings.Split(order[len(direction)+1:len(order)-1], ",")
+		name := strings.TrimSpace(arguments[0])
+		switch len(arguments) {
+		case 1:
+			return name, direction, definition.NullsDefault, nil
+		case 2:
+			switch strings.ToLower(strings.TrimSpace(arguments[1])) {
+			case "nullsfirst":
+				return name, direction, definition.NullsFirst, nil
+			case "nullslast":
+				return name, direction, definition.NullsLast, nil
+			}
+		}
+		return "", "", definition.NullsDefault, NewInvalidOrderError(order)
+	}
+	return order, "asc", definition.NullsDefault, nil
+}
+
+// newOrder validates the name and returns the order.
+func newOrder(name, direction string, nulls definition.Nulls, isNameChar func(rune) bool) (*definition.Order, error) {
+	if !binding.ValidName(name, isNameChar) {
+		return nil, NewInvalidNameError(name)
+	}
+	order := definition.NewOrder(name, direction)
+	order.SetNulls(nulls)
+	return order, nil
 }
 
+// parseSort parses the values of a JSON:API sort parameter like
+// "-created,name". A leading "-" marks a descending order.
+func parseSort(values []string, isNameChar func(rune) bool) ([]*definition.Order, []error) {
+	orders := []*definition.Order{}
+	errs := []error{}
+	for _, name := range commaEntries(values) {
+		direction := "asc"
+		if strings.HasPrefix(name, "-") {
+			direction = "desc"
+			name = name[1:]
+		}
+		order, err := newOrder(name, direction, definition.NullsDefault, isNameChar)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		orders = append(orders, order)
+	}
+	return orders, errs
+}
+
+// commaEntries returns the non empty comma separated entries of JSON:API
+// parameter values like the ones of sort, fields[type] and include.
+func commaEntries(values []string) []string {
+	entries := []string{}
+	for _, value := range values {
+		for _, entry := range strings.Split(value, ",") {
+			entry = strings.TrimSpace(entry)
+			if len(entry) > 0 {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries
+}
+
+// BindingShape describes the tree which is created by the default query binding.
+type BindingShape int
+
+const (
+	// BindingRightDeep nests the AND-Statements on the right side: a&(b&c).
+	BindingRightDeep BindingShape = iota
+	// BindingLeftDeep nests the AND-Statements on the left side: (a&b)&c.
+	BindingLeftDeep
+	// BindingBalanced creates a balanced tree: (a&b)&(c&d).
+	BindingBalanced
+)
+
 // ConstructDefaultQueryBinding creates a query binding where all parameters
-// are connected with an AND-Statement.
+// are connected with an AND-Statement. The parameters are sorted by their
+// alias and result in a right deep tree.
 func (v *ValueFilterArguments) ConstructDefaultQueryBinding() string {
-	args := v.arguments
-	data := make([]string, len(args))
-	index := 0
-	for key := range args {
-		data[index] = key
-		index++
-	}
-	return strings.Join(data, "&")
+	return v.ConstructShapedQueryBinding(BindingRightDeep)
+}
+
+// ConstructShapedQueryBinding creates a query binding where all parameters
+// are connected with an AND-Statement. The parameters are sorted by their
+// alias and nested according to the shape.
+func (v *ValueFilterArguments) ConstructShapedQueryBinding(shape BindingShape) string {
+	aliases := make([]string, 0, len(v.arguments))
+	for key := range v.arguments {
+		aliases = append(aliases, key)
+	}
+	sort.Strings(aliases)
+
+	switch shape {
+	case BindingLeftDeep:
+		binding := ""
+		for index, alias := range aliases {
+			switch index {
+			case 0:
+				binding = alias
+			case 1:
+				binding = binding + "&" + alias
+			default:
+				binding = "(" + binding + ")&" + alias
+			}
+		}
+		return binding
+	case BindingBalanced:
+		return balancedBinding(aliases)
+	}
+	return strings.Join(aliases, "&")
+}
+
+// balancedBinding joins the aliases to a balanced tree of AND-Statements.
+func balancedBinding(aliases []string) string {
+	if len(aliases) <= 1 {
+		return strings.Join(aliases, "")
+	}
+	middle := (len(aliases) + 1) / 2
+	left, right := balancedBinding(aliases[:middle]), balancedBinding(aliases[middle:])
+	if middle > 1 {
+		left = "(" + left + ")"
+	}
+	if len(aliases)-middle > 1 {
+		right = "(" + right + ")"
+	}
+	return left + "&" + right
 }
 
 // NewValueFilterArgument initializes the ValueFilterArguments struct
@@ -0,0 +1,40 @@
+package filterparams
+
+import (
+	"github.com/cbrand/go-filterparams/definition"
+
+	. "gopkg.in/check.v1"
+)
+
+var _ = Suite(&ValueFilterArgumentsTest{})
+
+type ValueFilterArgumentsTest struct {
+	arguments *ValueFilterArguments
+}
+
+func (t *ValueFilterArgumentsTest) SetUpTest(c *C) {
+	t.arguments = NewValueFilterArgument()
+	for _, alias := range []string{"e", "c", "a", "d", "b"} {
+		t.arguments.SetArgument(alias, definition.NewParameter(alias))
+	}
+}
+
+func (t *ValueFilterArgumentsTest) TestDefaultBinding(c *C) {
+	c.Assert(t.arguments.ConstructDefaultQueryBinding(), Equals, "a&b&c&d&e")
+}
+
+func (t *ValueFilterArgumentsTest) TestLeftDeepBinding(c *C) {
+	c.Assert(t.arguments.ConstructShapedQueryBinding(BindingLeftDeep), Equals, "(((a&b)&c)&d)&e")
+}
+
+func (t *ValueFilterArgumentsTest) TestBalancedBinding(c *C) {
+	c.Assert(t.arguments.ConstructShapedQueryBinding(BindingBalanced), Equals, "((a&b)&c)&(d&e)")
+}
+
+func (t *ValueFilterArgumentsTest) TestSingleBinding(c *C) {
+	arguments := NewValueFilterArgument()
+	arguments.SetArgument("a", definition.NewParameter("a"))
+	for _, shape := range []BindingShape{BindingRightDeep, BindingLeftDeep, BindingBalanced} {
+		c.Assert(arguments.ConstructShapedQueryBinding(shape), Equals, "a")
+	}
+}